@@ -5,21 +5,64 @@ import (
 	"erc20pump/internal/cfg"
 	"flag"
 	"github.com/ethereum/go-ethereum/common"
+	"strings"
+	"time"
 )
 
 // config loads configuration from cli flags.
 func config() *cfg.Config {
 	con := cfg.Config{}
-	var addr string
+	var addr, sfc, lists, mc, lbl, prices, sanctions string
 
 	flag.StringVar(&con.OperaURI, "opera", "https://rpcapi.fantom.network", "Address of the Fantom Opera RPC interface.")
 	flag.Uint64Var(&con.StartBlock, "block", 0, "Numeric ID of the first loaded block.")
 	flag.StringVar(&addr, "contract", "0x0", "Address of the contract being scanned for ERC20 transfers.")
+	flag.StringVar(&sfc, "sfc", "", "Address of the SFC staking contract (keep empty to use the known SFC of the connected chain)")
+	flag.StringVar(&mc, "multicall", "0xcA11bde05977b3631167028862bE2a173976CA11", "Address of the Multicall3 contract used to resolve token metadata in batches (keep empty to use direct calls)")
+	flag.StringVar(&con.AbiDir, "abi", "", "Path to a directory of contract ABI JSON files to decode events from (keep empty for built-in decoders only)")
+	flag.StringVar(&con.TokenStore, "tokens", "tokens.json", "Path to the file persisting known tokens metadata (keep empty to keep tokens in memory only)")
+	flag.StringVar(&con.TokenImport, "tokenimport", "", "Path to a file of tokens metadata to be imported into the tokens store on start")
+	flag.StringVar(&con.TokenExport, "tokenexport", "", "Path to a file to export the tokens store into; the app terminates after the export")
+	flag.StringVar(&lists, "tokenlist", "", "Comma separated paths to token list JSON files, ordered by priority")
+	flag.BoolVar(&con.TokenListAugment, "tokenlistaugment", false, "Use token lists only to complete on-chain token metadata instead of overriding them")
+	flag.BoolVar(&con.HistoricalTokens, "tokenhistory", false, "Resolve symbol, decimals and total supply of tokens at the block of the transfer (archive node needed)")
+	flag.DurationVar(&con.TokenRefresh, "tokenrefresh", 24*time.Hour, "Age of token metadata to be refreshed from the chain (0 to disable periodic refresh)")
+	flag.StringVar(&con.TokenDenyList, "tokendeny", "", "Path to a file of denied token addresses, one per line")
+	flag.BoolVar(&con.SuppressFlagged, "suppressflagged", false, "Drop transfers, approvals, mints and burns of tokens flagged as spam or scam from the output")
+	flag.BoolVar(&con.AmountHex, "amounthex", false, "Add hex encoded raw amounts to the output")
+	flag.BoolVar(&con.NativeTransactions, "nativetx", false, "Emit transactions sending FTM to the scanned contract even if they have no ERC20 logs")
+	flag.BoolVar(&con.InternalTransfers, "internaltx", false, "Collect FTM transferred by internal calls from transaction traces (debug API needed)")
+	flag.StringVar(&lbl, "labels", "", "Comma separated paths to address label files (JSON or CSV), ordered by priority")
+	flag.StringVar(&sanctions, "sanctions", "", "Comma separated sanctions or deny lists of addresses as name=path (or path only to name the list by the file); changed files are reloaded")
+	flag.BoolVar(&con.ClassifyAddresses, "classify", false, "Classify addresses as EOA or contract by their code")
+	flag.StringVar(&con.PriceFeeds, "pricefeeds", "", "Path to a JSON file mapping token addresses to Chainlink style USD price feeds")
+	flag.StringVar(&prices, "pricefiles", "", "Comma separated paths to historical USD price files (JSON or CSV) of tokens without a price feed")
+	flag.DurationVar(&con.PriceMaxAge, "pricemaxage", 24*time.Hour, "Maximal age of a price from the price feeds or the price files (0 for no limit)")
+	flag.IntVar(&con.SchemaVersion, "schema", 1, "Version of the output records schema (1 or 2)")
 	flag.StringVar(&con.AwsRegion, "awsregion", "eu-central-1", "The AWS region to upload the JSONs to")
 	flag.StringVar(&con.AwsStream, "awsstream", "", "The Kinesis stream to upload the JSONs to (keep empty to generate local json files)")
+	flag.StringVar(&con.AwsAdminStream, "awsadminstream", "", "The Kinesis stream to upload the ADMIN records of the schema v2 to (keep empty to use the main stream)")
+	flag.StringVar(&con.AwsSanctionStream, "awssanctionstream", "", "The Kinesis stream to upload a copy of records with sanctioned addresses to (keep empty to disable)")
 	flag.Parse()
 
 	// decode contract address
 	con.ScanContract = common.HexToAddress(addr)
+	con.MulticallContract = common.HexToAddress(mc)
+	if lists != "" {
+		con.TokenLists = strings.Split(lists, ",")
+	}
+	if prices != "" {
+		con.PriceFiles = strings.Split(prices, ",")
+	}
+	if lbl != "" {
+		con.LabelFiles = strings.Split(lbl, ",")
+	}
+	if sanctions != "" {
+		con.SanctionLists = strings.Split(sanctions, ",")
+	}
+	if sfc != "" {
+		adr := common.HexToAddress(sfc)
+		con.SfcContract = &adr
+	}
 	return &con
 }
@@ -11,13 +11,25 @@ import (
 
 // main provides application entry point
 func main() {
+	c := config()
+
+	// export known tokens only, if requested
+	if c.TokenExport != "" {
+		if err := scanner.ExportTokens(c); err != nil {
+			log.Fatalf("can not export tokens; %s", err.Error())
+		}
+		log.Println("tokens exported to", c.TokenExport)
+		return
+	}
+
 	// make the scanner
-	s, err := scanner.New(config())
+	s, err := scanner.New(c)
 	if err != nil {
 		return
 	}
 
 	captureTerminate(s)
+	captureRefresh(s)
 
 	// start the scanner
 	s.Run()
@@ -36,3 +48,15 @@ func captureTerminate(s *scanner.Service) {
 		s.Stop()
 	}()
 }
+
+// captureRefresh setups token metadata refresh signal observation.
+func captureRefresh(s *scanner.Service) {
+	rs := make(chan os.Signal, 1)
+	signal.Notify(rs, syscall.SIGHUP)
+
+	go func() {
+		for range rs {
+			s.RefreshTokens()
+		}
+	}()
+}
@@ -1,14 +1,49 @@
 // Package cfg represents a structure of app config.
 package cfg
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"time"
+)
 
 // Config represents the app configuration.
 type Config struct {
 	OperaURI     string
 	StartBlock   uint64
 	ScanContract common.Address
+	SfcContract  *common.Address
+	AbiDir       string
 
-	AwsRegion string
-	AwsStream string
+	MulticallContract common.Address
+
+	TokenStore  string
+	TokenImport string
+	TokenExport string
+
+	TokenLists       []string
+	TokenListAugment bool
+	HistoricalTokens bool
+	TokenRefresh     time.Duration
+
+	TokenDenyList   string
+	SuppressFlagged bool
+
+	AmountHex          bool
+	NativeTransactions bool
+	InternalTransfers  bool
+
+	LabelFiles        []string
+	ClassifyAddresses bool
+	SanctionLists     []string
+
+	PriceFeeds  string
+	PriceFiles  []string
+	PriceMaxAge time.Duration
+
+	SchemaVersion int
+
+	AwsRegion         string
+	AwsStream         string
+	AwsAdminStream    string
+	AwsSanctionStream string
 }
@@ -0,0 +1,206 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"erc20pump/internal/trx"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// loadAbiDecoders builds generic event decoders for all the events found in contract ABI files
+// inside the given directory. Both plain ABI arrays and compiler artifacts with the "abi" key are accepted.
+// The directory must exist, so a mistyped path is not mistaken for a directory without ABI files.
+func loadAbiDecoders(dir string) (map[common.Hash]EventDecoder, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		log.Println("can not open ABI directory", dir, err.Error())
+		return nil, err
+	}
+	if !fi.IsDir() {
+		log.Println("ABI path is not a directory", dir)
+		return nil, fmt.Errorf("ABI path %s is not a directory", dir)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make(map[common.Hash][]abi.Event)
+	for _, fn := range files {
+		def, err := loadAbi(fn)
+		if err != nil {
+			log.Println("can not load ABI", fn, err.Error())
+			return nil, err
+		}
+
+		for _, ev := range def.Events {
+			// anonymous events do not have the signature topic to match against
+			if ev.Anonymous {
+				continue
+			}
+
+			// events sharing the signature may differ in the arguments indexed, e.g. ERC-20 and ERC-721 Transfer
+			if hasVariant(variants[ev.ID], len(indexedArgs(ev))) {
+				log.Println("duplicate ABI event", ev.Sig, "in", fn)
+				continue
+			}
+			variants[ev.ID] = append(variants[ev.ID], ev)
+		}
+
+		log.Println("ABI loaded", fn, len(def.Events), "events")
+	}
+
+	list := make(map[common.Hash]EventDecoder, len(variants))
+	for id, evs := range variants {
+		list[id] = abiDecoder(evs)
+	}
+	return list, nil
+}
+
+// hasVariant checks if the list of event variants contains a variant with the given number of indexed arguments.
+func hasVariant(list []abi.Event, indexed int) bool {
+	for _, ev := range list {
+		if len(indexedArgs(ev)) == indexed {
+			return true
+		}
+	}
+	return false
+}
+
+// indexedArgs provides the indexed arguments of the event.
+func indexedArgs(def abi.Event) abi.Arguments {
+	var indexed abi.Arguments
+	for _, in := range def.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	return indexed
+}
+
+// loadAbi reads the contract ABI definition from the given file.
+func loadAbi(fn string) (*abi.ABI, error) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// compiler artifacts (Truffle, Hardhat) wrap the ABI inside an object
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var art struct {
+			Abi json.RawMessage `json:"abi"`
+		}
+
+		if err := json.Unmarshal(data, &art); err != nil {
+			return nil, err
+		}
+		if art.Abi == nil {
+			return nil, fmt.Errorf("ABI not found in %s", fn)
+		}
+		data = art.Abi
+	}
+
+	def, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// abiDecoder creates a generic decoder of the given variants of an ABI event sharing the signature.
+// The variant is chosen by the number of indexed arguments matching the number of topics of the log.
+func abiDecoder(variants []abi.Event) EventDecoder {
+	decoders := make(map[int]EventDecoder, len(variants))
+	for _, def := range variants {
+		decoders[len(indexedArgs(def))] = abiEventDecoder(def)
+	}
+
+	return func(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+		dec, ok := decoders[len(ev.Topics)-1]
+		if !ok {
+			return trx.Erc20Transaction{}, fmt.Errorf("no %s variant with %d indexed arguments", variants[0].Sig, len(ev.Topics)-1)
+		}
+		return dec(ev, token)
+	}
+}
+
+// abiEventDecoder creates a generic decoder of the given ABI event.
+func abiEventDecoder(def abi.Event) EventDecoder {
+	indexed := indexedArgs(def)
+	return func(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+		values := make(map[string]interface{}, len(def.Inputs))
+		if err := abi.ParseTopicsIntoMap(values, indexed, ev.Topics[1:]); err != nil {
+			return trx.Erc20Transaction{}, fmt.Errorf("invalid %s topics; %s", def.Sig, err.Error())
+		}
+
+		if err := def.Inputs.NonIndexed().UnpackIntoMap(values, ev.Data); err != nil {
+			return trx.Erc20Transaction{}, fmt.Errorf("invalid %s data; %s", def.Sig, err.Error())
+		}
+
+		args := make([]trx.EventArgument, len(def.Inputs))
+		for i, in := range def.Inputs {
+			args[i] = trx.EventArgument{
+				Name:    in.Name,
+				Type:    in.Type.String(),
+				Indexed: in.Indexed,
+				Value:   abiValue(values[in.Name], in.Type),
+			}
+		}
+
+		return trx.Erc20Transaction{
+			Token: trx.Token{Address: ev.Address},
+			Type:  "EVENT",
+			Event: &trx.Event{
+				Name:      def.RawName,
+				Signature: def.Sig,
+				Arguments: args,
+			},
+		}, nil
+	}
+}
+
+// abiValue converts decoded ABI value of the given type into a JSON friendly representation.
+// Integers are rendered as base-10 strings so no precision is lost on the consumer side.
+func abiValue(v interface{}, t abi.Type) interface{} {
+	// indexed dynamic types are available only as the hash of the value
+	if h, ok := v.(common.Hash); ok {
+		return h.Hex()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return fmt.Sprintf("%d", v)
+	case abi.AddressTy:
+		return v.(common.Address).Hex()
+	case abi.BytesTy, abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		list := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			list[i] = abiValue(rv.Index(i).Interface(), *t.Elem)
+		}
+		return list
+	case abi.TupleTy:
+		tuple := make(map[string]interface{}, len(t.TupleElems))
+		for i, el := range t.TupleElems {
+			tuple[t.TupleRawNames[i]] = abiValue(rv.Field(i).Interface(), *el)
+		}
+		return tuple
+	}
+	return v
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+// erc20Abi represents the ERC-20 events with the value not indexed.
+const erc20Abi = `[
+	{"anonymous":false,"name":"Transfer","type":"event","inputs":[
+		{"indexed":true,"name":"from","type":"address"},
+		{"indexed":true,"name":"to","type":"address"},
+		{"indexed":false,"name":"value","type":"uint256"}]},
+	{"anonymous":false,"name":"Approval","type":"event","inputs":[
+		{"indexed":true,"name":"owner","type":"address"},
+		{"indexed":true,"name":"spender","type":"address"},
+		{"indexed":false,"name":"value","type":"uint256"}]}
+]`
+
+// erc721Artifact represents a compiler artifact with the ERC-721 events with the token ID indexed.
+const erc721Artifact = `{"contractName":"NFT","abi":[
+	{"anonymous":false,"name":"Transfer","type":"event","inputs":[
+		{"indexed":true,"name":"from","type":"address"},
+		{"indexed":true,"name":"to","type":"address"},
+		{"indexed":true,"name":"tokenId","type":"uint256"}]},
+	{"anonymous":false,"name":"Approval","type":"event","inputs":[
+		{"indexed":true,"name":"owner","type":"address"},
+		{"indexed":true,"name":"approved","type":"address"},
+		{"indexed":true,"name":"tokenId","type":"uint256"}]}
+]}`
+
+// approvalTopic represents the topic of the Approval(address,address,uint256) event.
+var approvalTopic = common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")
+
+// writeAbi stores the ABI definition in the given directory.
+func writeAbi(t *testing.T, dir string, name string, def string) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(def), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAbiDecodersErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := loadAbiDecoders(filepath.Join(dir, "missing")); err == nil {
+		t.Error("missing directory accepted")
+	}
+
+	writeAbi(t, dir, "erc20.json", erc20Abi)
+	if _, err := loadAbiDecoders(filepath.Join(dir, "erc20.json")); err == nil {
+		t.Error("file accepted as a directory")
+	}
+
+	writeAbi(t, dir, "broken.json", `{"contractName":"Broken"}`)
+	if _, err := loadAbiDecoders(dir); err == nil {
+		t.Error("artifact without ABI accepted")
+	}
+}
+
+func TestLoadAbiDecoders(t *testing.T) {
+	dir := t.TempDir()
+	writeAbi(t, dir, "erc20.json", erc20Abi)
+	writeAbi(t, dir, "erc721.json", erc721Artifact)
+	writeAbi(t, dir, "erc20copy.json", erc20Abi)
+
+	list, err := loadAbiDecoders(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("%d decoders loaded, expected 2", len(list))
+	}
+
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	id := common.BigToHash(big.NewInt(42))
+	value := common.BigToHash(big.NewInt(1000)).Bytes()
+
+	tests := []struct {
+		name  string
+		ev    types.Log
+		event string
+		args  []interface{}
+		fails bool
+	}{
+		{"erc20 transfer", types.Log{Topics: []common.Hash{erc20TransferTopic, from.Hash(), to.Hash()}, Data: value},
+			"Transfer", []interface{}{from.Hex(), to.Hex(), "1000"}, false},
+		{"erc721 transfer", types.Log{Topics: []common.Hash{erc20TransferTopic, from.Hash(), to.Hash(), id}},
+			"Transfer", []interface{}{from.Hex(), to.Hex(), "42"}, false},
+		{"erc20 approval", types.Log{Topics: []common.Hash{approvalTopic, from.Hash(), to.Hash()}, Data: value},
+			"Approval", []interface{}{from.Hex(), to.Hex(), "1000"}, false},
+		{"erc721 approval", types.Log{Topics: []common.Hash{approvalTopic, from.Hash(), to.Hash(), id}},
+			"Approval", []interface{}{from.Hex(), to.Hex(), "42"}, false},
+		{"unknown variant", types.Log{Topics: []common.Hash{approvalTopic, from.Hash()}, Data: value}, "", nil, true},
+		{"missing data", types.Log{Topics: []common.Hash{erc20TransferTopic, from.Hash(), to.Hash()}}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec, ok := list[tt.ev.Topics[0]]
+			if !ok {
+				t.Fatal("decoder not found")
+			}
+
+			et, err := dec(&tt.ev, func(common.Address) trx.Token { return trx.Token{} })
+			if tt.fails {
+				if err == nil {
+					t.Fatal("invalid log decoded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if et.Type != "EVENT" || et.Event == nil || et.Event.Name != tt.event {
+				t.Fatalf("unexpected entry %+v", et)
+			}
+			if len(et.Event.Arguments) != len(tt.args) {
+				t.Fatalf("%d arguments decoded, expected %d", len(et.Event.Arguments), len(tt.args))
+			}
+			for i, arg := range et.Event.Arguments {
+				if arg.Value != tt.args[i] {
+					t.Errorf("argument %s is %v, expected %v", arg.Name, arg.Value, tt.args[i])
+				}
+			}
+		})
+	}
+}
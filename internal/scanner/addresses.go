@@ -0,0 +1,119 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/scanner/cache"
+	"erc20pump/internal/scanner/labels"
+	"erc20pump/internal/scanner/rpc"
+	"erc20pump/internal/scanner/sanctions"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"log"
+)
+
+// addressEnricher represents an enrichment of transaction addresses with labels, address kinds and sanctions lists hits.
+type addressEnricher struct {
+	labels    *labels.Labels
+	sanctions *sanctions.Sanctions
+	classify  bool
+	rpc       *rpc.Adapter
+	cache     *cache.MemCache
+}
+
+// newAddressEnricher creates a new address enricher. If classify is set, addresses are classified
+// as EOA or contract by their code.
+func newAddressEnricher(lbl *labels.Labels, sl *sanctions.Sanctions, classify bool, rpc *rpc.Adapter, cache *cache.MemCache) *addressEnricher {
+	return &addressEnricher{
+		labels:    lbl,
+		sanctions: sl,
+		classify:  classify,
+		rpc:       rpc,
+		cache:     cache,
+	}
+}
+
+// enrich adds labels, kinds and sanctions lists hits of all the addresses of the transaction, including its entries.
+func (ae *addressEnricher) enrich(tx *trx.BlockchainTransaction) {
+	ae.classifyAll(tx)
+
+	tx.FromLabel, tx.FromKind = ae.describe(tx.From)
+	tx.ToLabel, tx.ToKind = ae.describe(tx.To)
+	tx.FromSanctions = ae.screen(tx, tx.From)
+	tx.ToSanctions = ae.screen(tx, tx.To)
+
+	for i := range tx.Transactions {
+		et := &tx.Transactions[i]
+		et.SenderLabel, et.SenderKind = ae.describe(et.Sender)
+		et.RecipientLabel, et.RecipientKind = ae.describe(et.Recipient)
+		et.SenderSanctions = ae.screen(tx, et.Sender)
+		et.RecipientSanctions = ae.screen(tx, et.Recipient)
+	}
+}
+
+// classifyAll loads kinds of all the addresses of the transaction not known yet in a single batch,
+// so the addresses are not checked one by one.
+func (ae *addressEnricher) classifyAll(tx *trx.BlockchainTransaction) {
+	if !ae.classify {
+		return
+	}
+
+	seen := map[common.Address]bool{{}: true}
+	list := make([]common.Address, 0)
+	add := func(adr common.Address) {
+		if !seen[adr] && !ae.cache.HasIsContract(adr) {
+			list = append(list, adr)
+		}
+		seen[adr] = true
+	}
+
+	add(tx.From)
+	add(tx.To)
+	for _, et := range tx.Transactions {
+		add(et.Sender)
+		add(et.Recipient)
+	}
+
+	if len(list) == 0 {
+		return
+	}
+	for adr, is := range ae.rpc.AreContracts(list) {
+		ae.cache.SetIsContract(adr, is)
+	}
+}
+
+// screen provides names of the sanctions lists containing the address of the transaction, if any.
+func (ae *addressEnricher) screen(tx *trx.BlockchainTransaction, adr common.Address) []string {
+	if adr == (common.Address{}) {
+		return nil
+	}
+
+	lists := ae.sanctions.Lists(adr)
+	if len(lists) > 0 {
+		log.Println("listed address", adr.String(), lists, "in transaction", tx.TXHash.String())
+	}
+	return lists
+}
+
+// describe provides the label and the kind of the address; unknown values are empty.
+// The zero address used for mints and burns is not described.
+func (ae *addressEnricher) describe(adr common.Address) (string, string) {
+	if adr == (common.Address{}) {
+		return "", ""
+	}
+
+	label := ae.labels.Label(adr)
+	if !ae.classify {
+		return label, ""
+	}
+
+	is, err := ae.cache.IsContract(adr, ae.rpc.IsContract)
+	if err != nil {
+		log.Println("address kind not available", adr.String(), err.Error())
+		return label, ""
+	}
+
+	if is {
+		return label, trx.KindContract
+	}
+	return label, trx.KindEOA
+}
@@ -0,0 +1,205 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// Topics of the administrative events.
+var (
+	ownershipTransferredTopic = common.HexToHash("0x8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e0")
+	roleGrantedTopic          = common.HexToHash("0x2f8788117e7eff1d82e926ec794901d17c78024a50270940304540a733656f0d")
+	roleRevokedTopic          = common.HexToHash("0xf6391f5c32d9c69d2a47ea670b442974b53935d1edc7fd64eb21e047a839171b")
+	pausedTopic               = common.HexToHash("0x62e78cea01bee320cd4e420270b5ea74000d11b0c9f74754ebdbfc544b05a258")
+	unpausedTopic             = common.HexToHash("0x5db9ee0a495bf2e6ff9c91a7834c1ba4fdd244a5e8aa4e537bd38aeae4b073aa")
+	upgradedTopic             = common.HexToHash("0xbc7cd75a20ee27fd9adebab32041f755214dbc6bffa90cc0225b39da2e5c2d3b")
+	adminChangedTopic         = common.HexToHash("0x7e644d79422f17c01e4894b5f4f588d331ebfa28653d42ae832dc59e38c9798f")
+	proposalCreatedTopic      = common.HexToHash("0x7d84a6263ae0d98d3329bd7b46bb4e8d6f98cd35a7adb45c274c8b7fd5ebd5e0")
+	voteCastTopic             = common.HexToHash("0xb8e138887d0aa13bab447e82de9d5c1777041ecd21ca36ba824ff1e6c07ddda4")
+)
+
+// adminTopics represents the topics of the administrative events. These events are matched on the watched contract
+// emitting them as well, since administrative changes are usually sent through a multisig or a timelock.
+var adminTopics = map[common.Hash]bool{
+	ownershipTransferredTopic: true,
+	roleGrantedTopic:          true,
+	roleRevokedTopic:          true,
+	pausedTopic:               true,
+	unpausedTopic:             true,
+	upgradedTopic:             true,
+	adminChangedTopic:         true,
+	proposalCreatedTopic:      true,
+	voteCastTopic:             true,
+}
+
+// proposalCreatedArgs represents the arguments of the Governor proposal event.
+var proposalCreatedArgs = abi.Arguments{
+	{Name: "proposalId", Type: abiType("uint256")},
+	{Name: "proposer", Type: abiType("address")},
+	{Name: "targets", Type: abiType("address[]")},
+	{Name: "values", Type: abiType("uint256[]")},
+	{Name: "signatures", Type: abiType("string[]")},
+	{Name: "calldatas", Type: abiType("bytes[]")},
+	{Name: "startBlock", Type: abiType("uint256")},
+	{Name: "endBlock", Type: abiType("uint256")},
+	{Name: "description", Type: abiType("string")},
+}
+
+// voteCastArgs represents the non-indexed arguments of the Governor vote event.
+var voteCastArgs = abi.Arguments{
+	{Name: "proposalId", Type: abiType("uint256")},
+	{Name: "support", Type: abiType("uint8")},
+	{Name: "weight", Type: abiType("uint256")},
+	{Name: "reason", Type: abiType("string")},
+}
+
+// decodeOwnershipTransferred decodes contract ownership change event.
+// Solidity: event OwnershipTransferred(address indexed previousOwner, address indexed newOwner)
+func decodeOwnershipTransferred(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 3 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid ownership transfer at %s", ev.Address.String())
+	}
+
+	return adminEntry(ev, "OWNERSHIP_TRANSFERRED",
+		common.BytesToAddress(ev.Topics[1].Bytes()), common.BytesToAddress(ev.Topics[2].Bytes()), &trx.Admin{}, token), nil
+}
+
+// decodeRoleGranted decodes access control role grant event.
+// Solidity: event RoleGranted(bytes32 indexed role, address indexed account, address indexed sender)
+func decodeRoleGranted(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	return decodeRole(ev, "ROLE_GRANTED", token)
+}
+
+// decodeRoleRevoked decodes access control role revoke event.
+// Solidity: event RoleRevoked(bytes32 indexed role, address indexed account, address indexed sender)
+func decodeRoleRevoked(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	return decodeRole(ev, "ROLE_REVOKED", token)
+}
+
+// decodeRole decodes access control role change event of the given type.
+func decodeRole(ev *types.Log, typ string, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 4 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid role change at %s", ev.Address.String())
+	}
+
+	return adminEntry(ev, typ,
+		common.BytesToAddress(ev.Topics[3].Bytes()), common.BytesToAddress(ev.Topics[2].Bytes()),
+		&trx.Admin{Role: ev.Topics[1].Hex()}, token), nil
+}
+
+// decodePaused decodes contract pause event.
+// Solidity: event Paused(address account)
+func decodePaused(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 1 || len(ev.Data) < 32 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid pause at %s", ev.Address.String())
+	}
+	return adminEntry(ev, "PAUSED", common.BytesToAddress(ev.Data[:32]), ev.Address, &trx.Admin{}, token), nil
+}
+
+// decodeUnpaused decodes contract un-pause event.
+// Solidity: event Unpaused(address account)
+func decodeUnpaused(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 1 || len(ev.Data) < 32 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid un-pause at %s", ev.Address.String())
+	}
+	return adminEntry(ev, "UNPAUSED", common.BytesToAddress(ev.Data[:32]), ev.Address, &trx.Admin{}, token), nil
+}
+
+// decodeUpgraded decodes EIP-1967 proxy implementation upgrade event.
+// Solidity: event Upgraded(address indexed implementation)
+func decodeUpgraded(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 2 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid upgrade at %s", ev.Address.String())
+	}
+	return adminEntry(ev, "UPGRADED", ev.Address, common.BytesToAddress(ev.Topics[1].Bytes()), &trx.Admin{}, token), nil
+}
+
+// decodeAdminChanged decodes EIP-1967 proxy admin change event.
+// Solidity: event AdminChanged(address previousAdmin, address newAdmin)
+func decodeAdminChanged(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 1 || len(ev.Data) < 64 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid admin change at %s", ev.Address.String())
+	}
+	return adminEntry(ev, "ADMIN_CHANGED", common.BytesToAddress(ev.Data[:32]), common.BytesToAddress(ev.Data[32:64]), &trx.Admin{}, token), nil
+}
+
+// decodeProposalCreated decodes Governor proposal event.
+// Solidity: event ProposalCreated(uint256 proposalId, address proposer, address[] targets, uint256[] values, string[] signatures, bytes[] calldatas, uint256 startBlock, uint256 endBlock, string description)
+func decodeProposalCreated(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := proposalCreatedArgs.UnpackValues(ev.Data)
+	if err != nil || len(ev.Topics) != 1 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid proposal at %s", ev.Address.String())
+	}
+
+	return adminEntry(ev, "PROPOSAL_CREATED", val[1].(common.Address), ev.Address, &trx.Admin{
+		ProposalID:  val[0].(*big.Int).String(),
+		Targets:     val[2].([]common.Address),
+		StartBlock:  val[6].(*big.Int).String(),
+		EndBlock:    val[7].(*big.Int).String(),
+		Description: val[8].(string),
+	}, token), nil
+}
+
+// decodeVoteCast decodes Governor vote event.
+// Solidity: event VoteCast(address indexed voter, uint256 proposalId, uint8 support, uint256 weight, string reason)
+func decodeVoteCast(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := voteCastArgs.UnpackValues(ev.Data)
+	if err != nil || len(ev.Topics) != 2 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid vote at %s", ev.Address.String())
+	}
+
+	support := val[1].(uint8)
+	return adminEntry(ev, "VOTE_CAST", common.BytesToAddress(ev.Topics[1].Bytes()), ev.Address, &trx.Admin{
+		ProposalID:  val[0].(*big.Int).String(),
+		Support:     &support,
+		Weight:      val[2].(*big.Int).String(),
+		Description: val[3].(string),
+	}, token), nil
+}
+
+// adminEntry builds the trx structure of an administrative change of the contract emitting the event.
+func adminEntry(ev *types.Log, typ string, from common.Address, to common.Address, adm *trx.Admin, token func(common.Address) trx.Token) trx.Erc20Transaction {
+	return trx.Erc20Transaction{
+		Token:     token(ev.Address),
+		Type:      typ,
+		Sender:    from,
+		Recipient: to,
+		Admin:     adm,
+	}
+}
+
+// splitAdmin separates administrative entries of the transaction into a dedicated admin record,
+// so consumers can subscribe to admin changes without receiving the transfers and vice versa.
+func splitAdmin(tx trx.BlockchainTransaction) (*trx.BlockchainTransaction, *trx.BlockchainTransaction) {
+	main := make([]trx.Erc20Transaction, 0, len(tx.Transactions))
+	admin := make([]trx.Erc20Transaction, 0)
+	for _, et := range tx.Transactions {
+		if et.Admin != nil {
+			admin = append(admin, et)
+		} else {
+			main = append(main, et)
+		}
+	}
+
+	tx.Category = trx.CategoryTransfer
+	if len(admin) == 0 {
+		return &tx, nil
+	}
+
+	atx := tx
+	atx.Category = trx.CategoryAdmin
+	atx.Transactions = admin
+	atx.Flows = nil
+
+	if len(main) == 0 {
+		return nil, &atx
+	}
+
+	tx.Transactions = main
+	return &tx, &atx
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"testing"
+)
+
+func TestSplitAdmin(t *testing.T) {
+	transfer := trx.Erc20Transaction{Type: "TRANSFER", Amount: "100"}
+	approval := trx.Erc20Transaction{Type: "APPROVAL", Amount: "5"}
+	owner := trx.Erc20Transaction{Type: "OWNERSHIP_TRANSFERRED", Admin: &trx.Admin{}}
+	paused := trx.Erc20Transaction{Type: "PAUSED", Admin: &trx.Admin{}}
+	flows := []trx.Flow{{Address: common.HexToAddress("0x1111111111111111111111111111111111111111"), Delta: "-100"}}
+
+	tests := []struct {
+		name  string
+		list  []trx.Erc20Transaction
+		main  []string
+		admin []string
+	}{
+		{"transfers only", []trx.Erc20Transaction{transfer, approval}, []string{"TRANSFER", "APPROVAL"}, nil},
+		{"admin only", []trx.Erc20Transaction{owner, paused}, nil, []string{"OWNERSHIP_TRANSFERRED", "PAUSED"}},
+		{"mixed", []trx.Erc20Transaction{transfer, owner, approval, paused}, []string{"TRANSFER", "APPROVAL"}, []string{"OWNERSHIP_TRANSFERRED", "PAUSED"}},
+	}
+
+	// types lists the entry types of the record, nil for a missing record
+	types := func(tx *trx.BlockchainTransaction) []string {
+		if tx == nil {
+			return nil
+		}
+		list := make([]string, len(tx.Transactions))
+		for i, et := range tx.Transactions {
+			list[i] = et.Type
+		}
+		return list
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := common.HexToHash("0x01")
+			main, admin := splitAdmin(trx.BlockchainTransaction{TXHash: hash, Transactions: tt.list, Flows: flows})
+
+			if got := types(main); !equalStrings(got, tt.main) {
+				t.Errorf("main record entries %v, expected %v", got, tt.main)
+			}
+			if got := types(admin); !equalStrings(got, tt.admin) {
+				t.Errorf("admin record entries %v, expected %v", got, tt.admin)
+			}
+
+			if main != nil {
+				if main.Category != trx.CategoryTransfer || main.TXHash != hash || len(main.Flows) != len(flows) {
+					t.Errorf("unexpected main record %+v", main)
+				}
+			}
+			if admin != nil {
+				if admin.Category != trx.CategoryAdmin || admin.TXHash != hash || admin.Flows != nil {
+					t.Errorf("unexpected admin record %+v", admin)
+				}
+			}
+		})
+	}
+}
+
+// equalStrings checks if both lists contain the same strings in the same order.
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"strings"
+)
+
+// amounts adds the decimal normalized amount, and the hex amount if requested, to the transaction.
+// The decimal amount is left empty if the decimals of the token are not known.
+func amounts(et *trx.Erc20Transaction, hex bool) {
+	raw, ok := new(big.Int).SetString(et.Amount, 10)
+	if !ok {
+		return
+	}
+
+	if et.Token.HasDecimals() {
+		et.AmountDecimal = decimalAmount(raw, int(et.Token.Decimals))
+	}
+	if hex {
+		et.AmountHex = hexutil.EncodeBig(raw)
+	}
+}
+
+// decimalAmount provides the exact decimal representation of the raw amount of a token with the given decimals.
+// Trailing zeros of the fractional part are dropped.
+func decimalAmount(raw *big.Int, decimals int) string {
+	digits := new(big.Int).Abs(raw).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if raw.Sign() < 0 {
+		whole = "-" + whole
+	}
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestDecimalAmount(t *testing.T) {
+	huge, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	tests := []struct {
+		name     string
+		raw      *big.Int
+		decimals int
+		want     string
+	}{
+		{"zero", big.NewInt(0), 18, "0"},
+		{"zero decimals", big.NewInt(12345), 0, "12345"},
+		{"zero decimals trailing zeros", big.NewInt(1000), 0, "1000"},
+		{"one wei", big.NewInt(1), 18, "0.000000000000000001"},
+		{"below one unit", big.NewInt(123456), 6, "0.123456"},
+		{"exactly one unit", big.NewInt(1000000), 6, "1"},
+		{"trailing zeros trimmed", big.NewInt(1500000), 6, "1.5"},
+		{"whole units", new(big.Int).Mul(big.NewInt(42), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)), 18, "42"},
+		{"negative", big.NewInt(-2500000), 6, "-2.5"},
+		{"negative below one unit", big.NewInt(-1), 18, "-0.000000000000000001"},
+		{"negative whole", big.NewInt(-3000), 3, "-3"},
+		{"negative zero decimals", big.NewInt(-7), 0, "-7"},
+		{"max uint256", huge, 18, "115792089237316195423570985008687907853269984665640564039457.584007913129639935"},
+		{"max uint256 no decimals", huge, 0, huge.String()},
+		{"more decimals than digits", big.NewInt(5), 77, "0." + strings.Repeat("0", 76) + "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decimalAmount(tt.raw, tt.decimals); got != tt.want {
+				t.Errorf("decimalAmount(%s, %d) = %s, expected %s", tt.raw.String(), tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package cache
 
 import (
 	"encoding/binary"
+	"encoding/json"
+	"erc20pump/internal/scanner/rpc"
 	"fmt"
 	"github.com/allegro/bigcache"
 	"github.com/ethereum/go-ethereum/common"
@@ -66,13 +68,141 @@ func (c *MemCache) TrxRecipient(tx common.Hash, load func(common.Hash) (common.A
 
 	a, err := load(tx)
 	if err != nil {
-		log.Fatalf("recipient not available; %s", err.Error())
 		return common.Address{}, err
 	}
 
-	if err := c.cache.Set(tx.String(), a.Bytes()); err != nil {
+	c.SetTrxRecipient(tx, a)
+	return a, nil
+}
+
+// SetTrxRecipient stores the recipient of a transaction.
+func (c *MemCache) SetTrxRecipient(tx common.Hash, adr common.Address) {
+	if err := c.cache.Set(tx.String(), adr.Bytes()); err != nil {
 		log.Printf("can not cache; %s", err.Error())
 	}
+}
 
-	return a, nil
+// TokenState provides cached state of the token at the given block.
+func (c *MemCache) TokenState(adr common.Address, blk uint64, load func(common.Address, uint64) (rpc.TokenState, error)) (rpc.TokenState, error) {
+	key := fmt.Sprintf("ts%s%x", adr.String(), blk)
+
+	var ts rpc.TokenState
+	data, err := c.cache.Get(key)
+	if err == nil && json.Unmarshal(data, &ts) == nil {
+		return ts, nil
+	}
+
+	// non cached - take the slow path
+	ts, err = load(adr, blk)
+	if err != nil {
+		return ts, err
+	}
+
+	data, err = json.Marshal(ts)
+	if err == nil {
+		err = c.cache.Set(key, data)
+	}
+	if err != nil {
+		log.Printf("can not cache; %s", err.Error())
+	}
+
+	return ts, nil
+}
+
+// TrxDetail provides cached execution details of a transaction by its hash.
+func (c *MemCache) TrxDetail(tx common.Hash, load func(common.Hash) (rpc.TrxDetail, error)) (rpc.TrxDetail, error) {
+	var td rpc.TrxDetail
+	data, err := c.cache.Get("trx" + tx.String())
+	if err == nil && json.Unmarshal(data, &td) == nil {
+		return td, nil
+	}
+
+	// non cached - take the slow path
+	td, err = load(tx)
+	if err != nil {
+		return td, err
+	}
+
+	c.SetTrxDetail(td)
+	return td, nil
+}
+
+// HasTrxDetail checks if execution details of the transaction are cached.
+func (c *MemCache) HasTrxDetail(tx common.Hash) bool {
+	_, err := c.cache.Get("trx" + tx.String())
+	return err == nil
+}
+
+// SetTrxDetail stores execution details of a transaction, including the recipient of the transaction.
+func (c *MemCache) SetTrxDetail(td rpc.TrxDetail) {
+	data, err := json.Marshal(td)
+	if err == nil {
+		err = c.cache.Set("trx"+td.Hash.String(), data)
+	}
+	if err != nil {
+		log.Printf("can not cache; %s", err.Error())
+	}
+
+	c.SetTrxRecipient(td.Hash, td.Recipient())
+}
+
+// IsContract provides cached classification of an address as a contract.
+func (c *MemCache) IsContract(adr common.Address, load func(common.Address) (bool, error)) (bool, error) {
+	data, err := c.cache.Get("code" + adr.String())
+	if err == nil && len(data) == 1 {
+		return data[0] == 1, nil
+	}
+
+	// non cached - take the slow path
+	is, err := load(adr)
+	if err != nil {
+		return false, err
+	}
+
+	c.SetIsContract(adr, is)
+	return is, nil
+}
+
+// HasIsContract checks if the classification of the address is cached.
+func (c *MemCache) HasIsContract(adr common.Address) bool {
+	_, err := c.cache.Get("code" + adr.String())
+	return err == nil
+}
+
+// SetIsContract stores the classification of an address as a contract.
+func (c *MemCache) SetIsContract(adr common.Address, is bool) {
+	var b byte
+	if is {
+		b = 1
+	}
+	if err := c.cache.Set("code"+adr.String(), []byte{b}); err != nil {
+		log.Printf("can not cache; %s", err.Error())
+	}
+}
+
+// Round provides cached price round of the aggregator contract at the given block.
+func (c *MemCache) Round(feed common.Address, blk uint64, load func(common.Address, uint64) (rpc.Round, error)) (rpc.Round, error) {
+	key := fmt.Sprintf("rd%s%x", feed.String(), blk)
+
+	var r rpc.Round
+	data, err := c.cache.Get(key)
+	if err == nil && json.Unmarshal(data, &r) == nil {
+		return r, nil
+	}
+
+	// non cached - take the slow path
+	r, err = load(feed, blk)
+	if err != nil {
+		return r, err
+	}
+
+	data, err = json.Marshal(r)
+	if err == nil {
+		err = c.cache.Set(key, data)
+	}
+	if err != nil {
+		log.Printf("can not cache; %s", err.Error())
+	}
+
+	return r, nil
 }
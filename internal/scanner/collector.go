@@ -5,44 +5,109 @@ import (
 	"bytes"
 	"erc20pump/internal/cfg"
 	"erc20pump/internal/scanner/cache"
+	"erc20pump/internal/scanner/reputation"
 	"erc20pump/internal/scanner/rpc"
 	"erc20pump/internal/trx"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"log"
+	"math"
 	"math/big"
 	"strconv"
 	"sync"
 	"time"
 )
 
+const (
+	// registryFlushPeriod represents the period of storing the token registry.
+	registryFlushPeriod = 30 * time.Second
+
+	// tokenRefreshCheck represents the period of refreshing the next batch of tokens.
+	tokenRefreshCheck = 10 * time.Second
+
+	// tokenRefreshBatch represents the maximal number of tokens refreshed together.
+	tokenRefreshBatch = 100
+
+	// senderRetries represents the number of attempts to load the sender of a transaction.
+	senderRetries = 3
+
+	// senderRetryDelay represents the delay between attempts to load the sender of a transaction.
+	senderRetryDelay = 2 * time.Second
+
+	// sanctionsReloadCheck represents the period of looking for changed sanctions list files.
+	sanctionsReloadCheck = 1 * time.Minute
+)
+
 // logCollector represents a service responsible for collecting patches of transfers
 type logCollector struct {
-	input      chan types.Log
-	output     chan trx.BlockchainTransaction
-	sigStop    chan bool
-	currentTrx *trx.BlockchainTransaction
-	tokens     map[common.Address]trx.Token
-	rpc        *rpc.Adapter
-	cache      *cache.MemCache
-	wg         *sync.WaitGroup
-}
-
-// LogTopicProcessor represents a map of base log topic to transaction type.
-var LogTopicProcessor = map[common.Hash]func(*types.Log, func(common.Address) trx.Token) trx.Erc20Transaction{
-	common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"): decodeErc20Transfer,
+	input        chan types.Log
+	output       chan trx.BlockchainTransaction
+	updates      chan trx.TokenUpdate
+	sigStop      chan bool
+	sigRefresh   chan bool
+	refreshQueue []common.Address
+	currentTrx   *trx.BlockchainTransaction
+	tokens       *tokenResolver
+	addresses    *addressEnricher
+	prices       *priceEnricher
+	refreshAge   time.Duration
+	suppress     bool
+	amountHex    bool
+	traces       bool
+	decoders     map[common.Hash]EventDecoder
+	rpc          *rpc.Adapter
+	cache        *cache.MemCache
+	wg           *sync.WaitGroup
+}
+
+// erc20TransferTopic represents the topic of the ERC20 transfer event.
+var erc20TransferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+// LogTopicProcessor represents a map of base log topic to built-in transaction decoders.
+var LogTopicProcessor = map[common.Hash]EventDecoder{
+	erc20TransferTopic: decodeErc20Transfer,
+	common.HexToHash("0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"): decodeUniswapV2Swap,
+	common.HexToHash("0x4c209b5fc8ad50758f13e2e1088ba56a560dff690a1c6fef26394f4c03821c4f"): decodeUniswapV2Mint,
+	common.HexToHash("0xdccd412f0b1252819cb1fd330b93224ca42612892bb3f4f789976e6d81936496"): decodeUniswapV2Burn,
+	common.HexToHash("0x1c411e9a96e071241c2f21f7726b17ae89e3cab4c78be50e062b03a9fffbbad1"): decodeUniswapV2Sync,
+	common.HexToHash("0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67"): decodeUniswapV3Swap,
+	common.HexToHash("0xdcbc1c05240f31ff3ad067ef1ee35ce4997762752e3a095284754544f4c709d7"): decodeErc4626Deposit,
+	common.HexToHash("0xfbde797d201c681b91056529119e0b02407c7bb96a4a2c75c01fc9667232c8db"): decodeErc4626Withdraw,
+	common.HexToHash("0x06b541ddaa720db2b10a4d0cdac39b8d360425fc073085fac19bc82614677987"): decodeErc777Sent,
+	common.HexToHash("0x2fe5be0146f74c5bce36c0b80911af6c7d86ff27e89d5cfa61fc681327954e5d"): decodeErc777Minted,
+	common.HexToHash("0xa78a9be3a7b862d26933ad85fb11d80ef66b8f972d7cbba06621d583943a4098"): decodeErc777Burned,
+	ownershipTransferredTopic: decodeOwnershipTransferred,
+	roleGrantedTopic:          decodeRoleGranted,
+	roleRevokedTopic:          decodeRoleRevoked,
+	pausedTopic:               decodePaused,
+	unpausedTopic:             decodeUnpaused,
+	upgradedTopic:             decodeUpgraded,
+	adminChangedTopic:         decodeAdminChanged,
+	proposalCreatedTopic:      decodeProposalCreated,
+	voteCastTopic:             decodeVoteCast,
 	/* common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"): "APPROVAL", */
 }
 
 // newCollector creates a new log collector instance.
-func newCollector(_ *cfg.Config, in chan types.Log, rpc *rpc.Adapter, cache *cache.MemCache) *logCollector {
+// If traces is set, internal native transfers are collected from the transaction call traces.
+func newCollector(cfg *cfg.Config, in chan types.Log, dec map[common.Hash]EventDecoder, tokens *tokenResolver, adr *addressEnricher, prices *priceEnricher, traces bool, rpc *rpc.Adapter, cache *cache.MemCache) *logCollector {
 	return &logCollector{
-		input:   in,
-		output:  make(chan trx.BlockchainTransaction, 25),
-		tokens:  make(map[common.Address]trx.Token),
-		sigStop: make(chan bool, 1),
-		rpc:     rpc,
-		cache:   cache,
+		input:      in,
+		output:     make(chan trx.BlockchainTransaction, 25),
+		updates:    make(chan trx.TokenUpdate, 25),
+		tokens:     tokens,
+		addresses:  adr,
+		prices:     prices,
+		refreshAge: cfg.TokenRefresh,
+		suppress:   cfg.SuppressFlagged,
+		amountHex:  cfg.AmountHex,
+		traces:     traces,
+		decoders:   dec,
+		sigStop:    make(chan bool, 1),
+		sigRefresh: make(chan bool, 1),
+		rpc:        rpc,
+		cache:      cache,
 	}
 }
 
@@ -59,14 +124,31 @@ func (lc *logCollector) stop() {
 	lc.sigStop <- true
 }
 
+// refresh signals the log collector thread to refresh metadata of all the known tokens.
+func (lc *logCollector) refresh() {
+	select {
+	case lc.sigRefresh <- true:
+	default:
+		// refresh already pending
+	}
+}
+
 // collect interesting transactions and build collections for sending.
 func (lc *logCollector) collect() {
 	// auto-close pending transaction if no new event arrived in given time
 	tick := time.NewTicker(5 * time.Second)
+	flush := time.NewTicker(registryFlushPeriod)
+	refresh := time.NewTicker(tokenRefreshCheck)
+	reload := time.NewTicker(sanctionsReloadCheck)
 
 	defer func() {
 		tick.Stop()
+		flush.Stop()
+		refresh.Stop()
+		reload.Stop()
 		close(lc.output)
+		close(lc.updates)
+		lc.tokens.registry.Flush()
 
 		log.Println("log collector terminated")
 		lc.wg.Done()
@@ -80,6 +162,20 @@ func (lc *logCollector) collect() {
 		case <-tick.C:
 			lc.newTransaction(nil)
 
+		case <-flush.C:
+			lc.tokens.registry.Flush()
+
+		case <-refresh.C:
+			lc.refreshNext()
+
+		case <-reload.C:
+			lc.addresses.sanctions.Reload()
+
+		case <-lc.sigRefresh:
+			// all the tokens are refreshed in batches, so the events processing is not blocked
+			lc.refreshQueue = lc.tokens.stale(0, math.MaxInt32)
+			log.Println("refreshing all", len(lc.refreshQueue), "known tokens")
+
 		case ev := <-lc.input:
 			tick.Reset(5 * time.Second)
 			lc.process(ev)
@@ -94,15 +190,52 @@ func (lc *logCollector) process(ev types.Log) {
 		lc.newTransaction(&ev)
 	}
 
+	// transactions without logs of interest carry the native value only
+	if len(ev.Topics) == 0 {
+		return
+	}
+
 	// do we have a decoder for this type of event?
-	decode, ok := LogTopicProcessor[ev.Topics[0]]
+	decode, ok := lc.decoders[ev.Topics[0]]
 	if !ok {
 		log.Println("decoder lookup failed")
 		return
 	}
 
+	et, err := decode(&ev, func(adr common.Address) trx.Token {
+		return lc.tokens.token(adr, ev.BlockNumber)
+	})
+	if err != nil {
+		log.Println("can not decode event", ev.TxHash.String(), err.Error())
+		return
+	}
+
+	// proxy implementation changed, the token needs to be re-detected
+	if et.Type == "UPGRADED" {
+		lc.refreshTokens([]common.Address{ev.Address})
+		et.Token = lc.tokens.token(ev.Address, ev.BlockNumber)
+	}
+
+	// zero value transfers are used to poison address books
+	if et.Type == "TRANSFER" && et.Amount == "0" {
+		et.Token = lc.tokens.zeroTransfer(et.Token, ev.BlockNumber)
+	}
+
+	// only value moving entries are suppressed, never swaps of a pool or admin changes
+	et = lc.tokens.entry(et)
+	if lc.suppress && et.Token.Flagged && reputation.ValueEntry(et) {
+		log.Println("flagged token suppressed", et.Token.Address.String(), et.Token.Flags)
+		return
+	}
+
+	amounts(&et, lc.amountHex)
+	lc.prices.price(&et, ev.BlockNumber)
+
+	idx := ev.Index
+	et.LogIndex = &idx
+
 	// add decoded tx to the current transaction group
-	lc.currentTrx.Transactions = append(lc.currentTrx.Transactions, decode(&ev, lc.token))
+	lc.currentTrx.Transactions = append(lc.currentTrx.Transactions, et)
 }
 
 // newTransaction closes the current transaction, if any, and makes a new one.
@@ -110,7 +243,10 @@ func (lc *logCollector) newTransaction(ev *types.Log) {
 	// submit the current transaction
 	if lc.currentTrx != nil {
 		log.Println("closing group", lc.currentTrx.TXHash.String())
-		lc.output <- *lc.currentTrx
+		lc.currentTrx.Transactions = dedupErc777(lc.currentTrx.Transactions)
+		lc.addresses.enrich(lc.currentTrx)
+		lc.currentTrx.Flows = netFlows(lc.currentTrx)
+		lc.submit(*lc.currentTrx)
 	}
 
 	// no new log, just closing
@@ -125,22 +261,147 @@ func (lc *logCollector) newTransaction(ev *types.Log) {
 		From:         lc.sender(ev.TxHash),
 		To:           lc.recipient(ev.TxHash),
 		BlockNumber:  strconv.FormatUint(ev.BlockNumber, 10),
+		BlockHash:    ev.BlockHash,
+		TxIndex:      ev.TxIndex,
 		Timestamp:    lc.timestamp(ev.BlockNumber),
 		Transactions: make([]trx.Erc20Transaction, 0),
 	}
+	lc.execution(lc.currentTrx, ev.BlockNumber)
+	if lc.traces && lc.currentTrx.Status == trx.StatusSuccess {
+		lc.internalTransfers(lc.currentTrx, ev.BlockNumber)
+	}
 
 	log.Println("new group", ev.TxHash.String())
 }
 
+// refreshNext refreshes the next batch of the tokens waiting for the refresh of all the tokens, if any,
+// or the batch of tokens with metadata older than the refresh age otherwise.
+func (lc *logCollector) refreshNext() {
+	if len(lc.refreshQueue) == 0 {
+		if lc.refreshAge > 0 {
+			lc.refreshTokens(lc.tokens.stale(lc.refreshAge, tokenRefreshBatch))
+		}
+		return
+	}
+
+	size := tokenRefreshBatch
+	if size > len(lc.refreshQueue) {
+		size = len(lc.refreshQueue)
+	}
+
+	lc.refreshTokens(lc.refreshQueue[:size])
+	lc.refreshQueue = lc.refreshQueue[size:]
+}
+
+// refreshTokens refreshes metadata of the given tokens at the latest block and sends the changes found to the output.
+// The metadata of an older block are never stored, so the update is stamped with the time of the refresh
+// even if triggered by an event of a historical block.
+func (lc *logCollector) refreshTokens(adrs []common.Address) {
+	for _, u := range lc.tokens.refresh(adrs) {
+		u.Timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		lc.updates <- u
+	}
+}
+
+// submit sends the finished transaction to the output, admin entries are sent as a separate record.
+func (lc *logCollector) submit(tx trx.BlockchainTransaction) {
+	main, admin := splitAdmin(tx)
+	if main != nil && len(main.Transactions) > 0 {
+		lc.output <- *main
+	}
+	if admin != nil {
+		lc.output <- *admin
+	}
+}
+
 // decodeErc20Transfer decodes ERC20 transfer event log record into ERC20 trx structure.
 // Solidity: event Transfer(address indexed from, address indexed to, uint256 value)
-func decodeErc20Transfer(ev *types.Log, token func(common.Address) trx.Token) trx.Erc20Transaction {
+func decodeErc20Transfer(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	// ERC721 shares the signature, but the token ID is indexed
+	if len(ev.Topics) != 3 || len(ev.Data) < 32 {
+		return trx.Erc20Transaction{}, fmt.Errorf("not an ERC20 transfer at %s", ev.Address.String())
+	}
+
 	return trx.Erc20Transaction{
 		Token:     token(ev.Address),
 		Type:      "TRANSFER",
 		Sender:    common.BytesToAddress(ev.Topics[1].Bytes()),
 		Recipient: common.BytesToAddress(ev.Topics[2].Bytes()),
 		Amount:    new(big.Int).SetBytes(ev.Data[:32]).String(),
+	}, nil
+}
+
+// execution adds execution details from the transaction receipt to the transaction,
+// and the native value transferred by the transaction, or the contract creation mark.
+func (lc *logCollector) execution(tx *trx.BlockchainTransaction, blk uint64) {
+	td, err := lc.cache.TrxDetail(tx.TXHash, lc.rpc.TrxDetail)
+	if err != nil {
+		log.Println("no execution detail available for", tx.TXHash.String(), err.Error())
+		return
+	}
+
+	tx.BlockHash = td.BlockHash
+	tx.TxIndex = td.TxIndex
+
+	tx.Status = trx.StatusFailed
+	if td.Status == types.ReceiptStatusSuccessful {
+		tx.Status = trx.StatusSuccess
+	}
+
+	tx.Type = strconv.FormatUint(td.Type, 10)
+	tx.Nonce = strconv.FormatUint(td.Nonce, 10)
+	tx.GasUsed = strconv.FormatUint(td.GasUsed, 10)
+	if td.EffectiveGasPrice != nil {
+		tx.GasPrice = td.EffectiveGasPrice.String()
+	}
+
+	fee := td.Fee()
+	tx.Fee = fee.String()
+	tx.FeeDecimal = decimalAmount(fee, int(trx.NativeToken.Decimals))
+
+	// the native value is moved only if the transaction succeeded,
+	// a contract deployment is marked with the endowment of the new contract
+	created := td.To == nil && td.Created != nil
+	if tx.Status == trx.StatusSuccess && (created || (td.Value != nil && td.Value.Sign() > 0)) {
+		et := trx.Erc20Transaction{
+			Token:     trx.NativeToken,
+			Type:      "NATIVE_TRANSFER",
+			Sender:    tx.From,
+			Recipient: tx.To,
+			Amount:    "0",
+		}
+		if created {
+			et.Type = "CONTRACT_CREATION"
+		}
+		if td.Value != nil {
+			et.Amount = td.Value.String()
+		}
+		amounts(&et, lc.amountHex)
+		lc.prices.price(&et, blk)
+		tx.Transactions = append(tx.Transactions, et)
+	}
+}
+
+// internalTransfers adds native value transfers of internal calls to the transaction.
+func (lc *logCollector) internalTransfers(tx *trx.BlockchainTransaction, blk uint64) {
+	list, err := lc.rpc.InternalTransfers(tx.TXHash)
+	if err != nil {
+		log.Println("no internal transfers available for", tx.TXHash.String(), err.Error())
+		return
+	}
+
+	for _, it := range list {
+		et := trx.Erc20Transaction{
+			Token:     trx.NativeToken,
+			Type:      "INTERNAL_NATIVE",
+			Sender:    it.From,
+			Recipient: it.To,
+			Amount:    it.Value.String(),
+			Trace:     &trx.Trace{CallType: it.CallType, Depth: it.Depth},
+		}
+		amounts(&et, lc.amountHex)
+		lc.prices.price(&et, blk)
+		tx.Transactions = append(tx.Transactions, et)
 	}
 }
 
@@ -148,7 +409,7 @@ func decodeErc20Transfer(ev *types.Log, token func(common.Address) trx.Token) tr
 func (lc *logCollector) timestamp(blk uint64) string {
 	ts, err := lc.cache.BlockTime(blk, lc.rpc.BlockTime)
 	if err != nil {
-		log.Fatalf("block timestamp not available for %d; %s", blk, err.Error())
+		fatalf(lc.tokens.registry, "block timestamp not available for %d; %s", blk, err.Error())
 		return ""
 	}
 	return strconv.FormatUint(ts, 10)
@@ -158,58 +419,30 @@ func (lc *logCollector) timestamp(blk uint64) string {
 func (lc *logCollector) recipient(tx common.Hash) common.Address {
 	a, err := lc.cache.TrxRecipient(tx, lc.rpc.TrxRecipient)
 	if err != nil {
-		log.Fatalf("no recipient available for %s; %s", tx.String(), err.Error())
+		fatalf(lc.tokens.registry, "no recipient available for %s; %s", tx.String(), err.Error())
 		return common.Address{}
 	}
 	return a
 }
 
 // sender provides signing address of a transaction by its hash.
+// The lookup is retried; the app terminates if the sender is not available,
+// since a record without the real sender would skip the user flows and the sanctions screening.
 func (lc *logCollector) sender(tx common.Hash) common.Address {
-	a, err := lc.rpc.TrxSender(tx)
-	if err != nil {
-		log.Fatalf("no sender available for %s; %s", tx.String(), err.Error())
-		return common.Address{}
-	}
-	return a
-}
-
-// token provides an ERC20 detail structure based on token contract address.
-func (lc *logCollector) token(adr common.Address) trx.Token {
-	// do we already know the token?
-	tok, ok := lc.tokens[adr]
-	if ok {
-		return tok
-	}
-
-	// we need to pull the data from RPC
-	name, err := lc.rpc.Erc20Name(adr)
-	if err != nil {
-		log.Println("token name lookup failed", err.Error(), adr.Hex())
-		name = "unknown"
-	}
-
-	symbol, err := lc.rpc.Erc20Symbol(adr)
-	if err != nil {
-		log.Println("token symbol lookup failed", err.Error(), adr.Hex())
-		name = "-"
-	}
-
-	decimals, err := lc.rpc.Erc20Decimals(adr)
-	if err != nil {
-		log.Println("token decimals lookup failed", err.Error(), adr.Hex())
-		decimals = 0
-	}
+	var err error
+	var td rpc.TrxDetail
+	for i := 0; i < senderRetries; i++ {
+		if i > 0 {
+			log.Println("retrying sender of", tx.String(), err.Error())
+			time.Sleep(senderRetryDelay)
+		}
 
-	tok = trx.Token{
-		Address:  adr,
-		Name:     name,
-		Symbol:   symbol,
-		Decimals: decimals,
+		td, err = lc.cache.TrxDetail(tx, lc.rpc.TrxDetail)
+		if err == nil {
+			return td.From
+		}
 	}
 
-	log.Println("new token found", tok.Name, "/", tok.Symbol, "[", tok.Decimals, "]")
-	lc.tokens[adr] = tok
-
-	return tok
+	fatalf(lc.tokens.registry, "no sender available for %s; %s", tx.String(), err.Error())
+	return common.Address{}
 }
@@ -0,0 +1,66 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/cfg"
+	"erc20pump/internal/scanner/rpc"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log"
+)
+
+// EventDecoder represents a function decoding an event log record into an ERC20 trx structure.
+type EventDecoder func(*types.Log, func(common.Address) trx.Token) (trx.Erc20Transaction, error)
+
+// newDecoders builds the map of log topics to event decoders used by the scanner.
+// Generic decoders loaded from contract ABI files are overridden by the built-in ones.
+func newDecoders(cfg *cfg.Config, rpc *rpc.Adapter) (map[common.Hash]EventDecoder, error) {
+	list := make(map[common.Hash]EventDecoder)
+
+	if cfg.AbiDir != "" {
+		abi, err := loadAbiDecoders(cfg.AbiDir)
+		if err != nil {
+			return nil, err
+		}
+		for t, dec := range abi {
+			list[t] = dec
+		}
+	}
+
+	for t, dec := range LogTopicProcessor {
+		list[t] = dec
+	}
+
+	// staking events of the SFC contract
+	sfc, err := sfcContract(cfg, rpc)
+	if err != nil {
+		return nil, err
+	}
+	if sfc != nil {
+		for t, dec := range sfcDecoders(*sfc) {
+			list[t] = dec
+		}
+	}
+	return list, nil
+}
+
+// sfcContract provides the address of the SFC contract, either configured, or known for the connected chain.
+func sfcContract(cfg *cfg.Config, rpc *rpc.Adapter) (*common.Address, error) {
+	if cfg.SfcContract != nil {
+		return cfg.SfcContract, nil
+	}
+
+	id, err := rpc.ChainID()
+	if err != nil {
+		log.Println("can not get chain ID", err.Error())
+		return nil, err
+	}
+
+	sfc, ok := sfcContracts[id]
+	if !ok {
+		log.Println("SFC contract not known for chain", id)
+		return nil, nil
+	}
+	return &sfc, nil
+}
@@ -0,0 +1,152 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// decodeUniswapV2Swap decodes Uniswap V2 pair swap event into a swap trx structure.
+// Solidity: event Swap(address indexed sender, uint amount0In, uint amount1In, uint amount0Out, uint amount1Out, address indexed to)
+func decodeUniswapV2Swap(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, pair, err := dexValues(ev, 3, 4, token)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	// which direction the swap goes?
+	dex := trx.Dex{Protocol: "UNISWAP_V2", Pair: ev.Address}
+	if val[0].Sign() > 0 {
+		dex.TokenIn, dex.AmountIn = &pair.Pair.Token0, val[0].String()
+		dex.TokenOut, dex.AmountOut = &pair.Pair.Token1, val[3].String()
+	} else {
+		dex.TokenIn, dex.AmountIn = &pair.Pair.Token1, val[1].String()
+		dex.TokenOut, dex.AmountOut = &pair.Pair.Token0, val[2].String()
+	}
+
+	return trx.Erc20Transaction{
+		Token:     pair,
+		Type:      "SWAP",
+		Sender:    common.BytesToAddress(ev.Topics[1].Bytes()),
+		Recipient: common.BytesToAddress(ev.Topics[2].Bytes()),
+		Dex:       &dex,
+	}, nil
+}
+
+// decodeUniswapV3Swap decodes Uniswap V3 pool swap event into a swap trx structure.
+// Solidity: event Swap(address indexed sender, address indexed recipient, int256 amount0, int256 amount1, uint160 sqrtPriceX96, uint128 liquidity, int24 tick)
+func decodeUniswapV3Swap(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, pair, err := dexValues(ev, 3, 5, token)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	// positive amount is received by the pool, negative is paid out
+	a0, a1 := math.S256(val[0]), math.S256(val[1])
+
+	dex := trx.Dex{Protocol: "UNISWAP_V3", Pair: ev.Address}
+	if a0.Sign() > 0 {
+		dex.TokenIn, dex.AmountIn = &pair.Pair.Token0, a0.String()
+		dex.TokenOut, dex.AmountOut = &pair.Pair.Token1, new(big.Int).Neg(a1).String()
+	} else {
+		dex.TokenIn, dex.AmountIn = &pair.Pair.Token1, a1.String()
+		dex.TokenOut, dex.AmountOut = &pair.Pair.Token0, new(big.Int).Neg(a0).String()
+	}
+
+	return trx.Erc20Transaction{
+		Token:     pair,
+		Type:      "SWAP",
+		Sender:    common.BytesToAddress(ev.Topics[1].Bytes()),
+		Recipient: common.BytesToAddress(ev.Topics[2].Bytes()),
+		Dex:       &dex,
+	}, nil
+}
+
+// decodeUniswapV2Mint decodes Uniswap V2 pair liquidity deposit event.
+// Solidity: event Mint(address indexed sender, uint amount0, uint amount1)
+func decodeUniswapV2Mint(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, pair, err := dexValues(ev, 2, 2, token)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	return trx.Erc20Transaction{
+		Token:     pair,
+		Type:      "MINT",
+		Sender:    common.BytesToAddress(ev.Topics[1].Bytes()),
+		Recipient: ev.Address,
+		Dex: &trx.Dex{
+			Protocol: "UNISWAP_V2",
+			Pair:     ev.Address,
+			Amount0:  val[0].String(),
+			Amount1:  val[1].String(),
+		},
+	}, nil
+}
+
+// decodeUniswapV2Burn decodes Uniswap V2 pair liquidity withdrawal event.
+// Solidity: event Burn(address indexed sender, uint amount0, uint amount1, address indexed to)
+func decodeUniswapV2Burn(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, pair, err := dexValues(ev, 3, 2, token)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	return trx.Erc20Transaction{
+		Token:     pair,
+		Type:      "BURN",
+		Sender:    ev.Address,
+		Recipient: common.BytesToAddress(ev.Topics[2].Bytes()),
+		Dex: &trx.Dex{
+			Protocol: "UNISWAP_V2",
+			Pair:     ev.Address,
+			Amount0:  val[0].String(),
+			Amount1:  val[1].String(),
+		},
+	}, nil
+}
+
+// decodeUniswapV2Sync decodes Uniswap V2 pair reserves update event.
+// Solidity: event Sync(uint112 reserve0, uint112 reserve1)
+func decodeUniswapV2Sync(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, pair, err := dexValues(ev, 1, 2, token)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	return trx.Erc20Transaction{
+		Token:     pair,
+		Type:      "SYNC",
+		Sender:    ev.Address,
+		Recipient: ev.Address,
+		Dex: &trx.Dex{
+			Protocol: "UNISWAP_V2",
+			Pair:     ev.Address,
+			Reserve0: val[0].String(),
+			Reserve1: val[1].String(),
+		},
+	}, nil
+}
+
+// dexValues validates the DEX event layout and provides the non-indexed values
+// together with the pair token emitting the event.
+func dexValues(ev *types.Log, topics int, words int, token func(common.Address) trx.Token) ([]*big.Int, trx.Token, error) {
+	if len(ev.Topics) != topics || len(ev.Data) < words*32 {
+		return nil, trx.Token{}, fmt.Errorf("invalid DEX event at %s", ev.Address.String())
+	}
+
+	pair := token(ev.Address)
+	if pair.Pair == nil {
+		return nil, trx.Token{}, fmt.Errorf("pair tokens not available at %s", ev.Address.String())
+	}
+
+	val := make([]*big.Int, words)
+	for i := range val {
+		val[i] = new(big.Int).SetBytes(ev.Data[i*32 : (i+1)*32])
+	}
+	return val, pair, nil
+}
@@ -0,0 +1,60 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// decodeErc4626Deposit decodes ERC-4626 vault deposit event into a vault trx structure.
+// Solidity: event Deposit(address indexed sender, address indexed owner, uint256 assets, uint256 shares)
+func decodeErc4626Deposit(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 3 || len(ev.Data) < 64 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid vault deposit at %s", ev.Address.String())
+	}
+
+	vault := token(ev.Address)
+	owner := common.BytesToAddress(ev.Topics[2].Bytes())
+	return trx.Erc20Transaction{
+		Token:     vault,
+		Type:      "VAULT_DEPOSIT",
+		Sender:    common.BytesToAddress(ev.Topics[1].Bytes()),
+		Recipient: owner,
+		Amount:    new(big.Int).SetBytes(ev.Data[32:64]).String(),
+		Vault: &trx.Vault{
+			Asset:    vault.Asset,
+			Assets:   new(big.Int).SetBytes(ev.Data[:32]).String(),
+			Shares:   new(big.Int).SetBytes(ev.Data[32:64]).String(),
+			Owner:    owner,
+			Receiver: owner,
+		},
+	}, nil
+}
+
+// decodeErc4626Withdraw decodes ERC-4626 vault withdrawal event into a vault trx structure.
+// Solidity: event Withdraw(address indexed sender, address indexed receiver, address indexed owner, uint256 assets, uint256 shares)
+func decodeErc4626Withdraw(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 4 || len(ev.Data) < 64 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid vault withdrawal at %s", ev.Address.String())
+	}
+
+	vault := token(ev.Address)
+	owner := common.BytesToAddress(ev.Topics[3].Bytes())
+	return trx.Erc20Transaction{
+		Token:     vault,
+		Type:      "VAULT_WITHDRAW",
+		Sender:    owner,
+		Recipient: common.BytesToAddress(ev.Topics[2].Bytes()),
+		Amount:    new(big.Int).SetBytes(ev.Data[32:64]).String(),
+		Vault: &trx.Vault{
+			Asset:    vault.Asset,
+			Assets:   new(big.Int).SetBytes(ev.Data[:32]).String(),
+			Shares:   new(big.Int).SetBytes(ev.Data[32:64]).String(),
+			Owner:    owner,
+			Receiver: common.BytesToAddress(ev.Topics[2].Bytes()),
+		},
+	}, nil
+}
@@ -0,0 +1,118 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// erc777DataArgs represents the non-indexed arguments of the ERC-777 token events.
+var erc777DataArgs = abi.Arguments{
+	{Type: abiType("uint256")},
+	{Type: abiType("bytes")},
+	{Type: abiType("bytes")},
+}
+
+// decodeErc777Sent decodes ERC-777 token send event into ERC20 trx structure.
+// Solidity: event Sent(address indexed operator, address indexed from, address indexed to, uint256 amount, bytes data, bytes operatorData)
+func decodeErc777Sent(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 4 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid ERC-777 send at %s", ev.Address.String())
+	}
+	return erc777Entry(ev, "SENT", common.BytesToAddress(ev.Topics[2].Bytes()), common.BytesToAddress(ev.Topics[3].Bytes()), token)
+}
+
+// decodeErc777Minted decodes ERC-777 token mint event into ERC20 trx structure.
+// Solidity: event Minted(address indexed operator, address indexed to, uint256 amount, bytes data, bytes operatorData)
+func decodeErc777Minted(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 3 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid ERC-777 mint at %s", ev.Address.String())
+	}
+	return erc777Entry(ev, "MINTED", common.Address{}, common.BytesToAddress(ev.Topics[2].Bytes()), token)
+}
+
+// decodeErc777Burned decodes ERC-777 token burn event into ERC20 trx structure.
+// Solidity: event Burned(address indexed operator, address indexed from, uint256 amount, bytes data, bytes operatorData)
+func decodeErc777Burned(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	if len(ev.Topics) != 3 {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid ERC-777 burn at %s", ev.Address.String())
+	}
+	return erc777Entry(ev, "BURNED", common.BytesToAddress(ev.Topics[2].Bytes()), common.Address{}, token)
+}
+
+// erc777Entry builds ERC20 trx structure of an ERC-777 token operation.
+func erc777Entry(ev *types.Log, typ string, from common.Address, to common.Address, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := erc777DataArgs.UnpackValues(ev.Data)
+	if err != nil {
+		return trx.Erc20Transaction{}, fmt.Errorf("invalid ERC-777 data at %s; %s", ev.Address.String(), err.Error())
+	}
+
+	return trx.Erc20Transaction{
+		Token:     token(ev.Address),
+		Type:      typ,
+		Sender:    from,
+		Recipient: to,
+		Amount:    val[0].(*big.Int).String(),
+		Erc777: &trx.Erc777{
+			Operator:     common.BytesToAddress(ev.Topics[1].Bytes()),
+			Data:         hexData(val[1].([]byte)),
+			OperatorData: hexData(val[2].([]byte)),
+		},
+	}, nil
+}
+
+// dedupErc777 merges ERC-777 entries into the ERC20 transfers emitted by the same token for the same movement.
+// ERC-777 tokens emit both events for backward compatibility, so the pair would be counted twice otherwise.
+func dedupErc777(list []trx.Erc20Transaction) []trx.Erc20Transaction {
+	out := make([]trx.Erc20Transaction, 0, len(list))
+	used := make([]bool, len(list))
+
+	for i, et := range list {
+		if et.Erc777 == nil || et.Type == "TRANSFER" {
+			continue
+		}
+
+		for j := range list {
+			tr := &list[j]
+			if used[j] || tr.Type != "TRANSFER" || tr.Erc777 != nil || tr.Token.Address != et.Token.Address ||
+				tr.Sender != et.Sender || tr.Recipient != et.Recipient || tr.Amount != et.Amount {
+				continue
+			}
+
+			// attach the ERC-777 detail to the transfer and drop the duplicate
+			tr.Erc777 = et.Erc777
+			used[i], used[j] = true, true
+			break
+		}
+	}
+
+	for i, et := range list {
+		if used[i] && et.Type != "TRANSFER" {
+			continue
+		}
+		out = append(out, et)
+	}
+	return out
+}
+
+// hexData encodes the given binary data to hex, empty data are kept empty.
+func hexData(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return hexutil.Encode(b)
+}
+
+// abiType creates ABI type of the given elementary Solidity type.
+func abiType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"testing"
+)
+
+func TestDedupErc777(t *testing.T) {
+	tok := trx.Token{Address: common.HexToAddress("0x7777777777777777777777777777777777777777")}
+	other := trx.Token{Address: common.HexToAddress("0x2020202020202020202020202020202020202020")}
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	op := &trx.Erc777{Operator: alice, Data: "0x01"}
+
+	entry := func(tok trx.Token, typ string, from, to common.Address, amount string, e *trx.Erc777) trx.Erc20Transaction {
+		return trx.Erc20Transaction{Token: tok, Type: typ, Sender: from, Recipient: to, Amount: amount, Erc777: e}
+	}
+
+	tests := []struct {
+		name string
+		list []trx.Erc20Transaction
+		want []trx.Erc20Transaction
+	}{
+		{
+			name: "sent and transfer",
+			list: []trx.Erc20Transaction{
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(tok, "TRANSFER", alice, bob, "100", nil),
+			},
+			want: []trx.Erc20Transaction{entry(tok, "TRANSFER", alice, bob, "100", op)},
+		},
+		{
+			name: "minted and transfer from zero",
+			list: []trx.Erc20Transaction{
+				entry(tok, "TRANSFER", common.Address{}, bob, "5", nil),
+				entry(tok, "MINTED", common.Address{}, bob, "5", op),
+			},
+			want: []trx.Erc20Transaction{entry(tok, "TRANSFER", common.Address{}, bob, "5", op)},
+		},
+		{
+			name: "burned and transfer to zero",
+			list: []trx.Erc20Transaction{
+				entry(tok, "BURNED", alice, common.Address{}, "7", op),
+				entry(tok, "TRANSFER", alice, common.Address{}, "7", nil),
+			},
+			want: []trx.Erc20Transaction{entry(tok, "TRANSFER", alice, common.Address{}, "7", op)},
+		},
+		{
+			name: "lone transfer",
+			list: []trx.Erc20Transaction{entry(tok, "TRANSFER", alice, bob, "100", nil)},
+			want: []trx.Erc20Transaction{entry(tok, "TRANSFER", alice, bob, "100", nil)},
+		},
+		{
+			name: "transfer of another token",
+			list: []trx.Erc20Transaction{
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(other, "TRANSFER", alice, bob, "100", nil),
+			},
+			want: []trx.Erc20Transaction{
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(other, "TRANSFER", alice, bob, "100", nil),
+			},
+		},
+		{
+			name: "different amount",
+			list: []trx.Erc20Transaction{
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(tok, "TRANSFER", alice, bob, "99", nil),
+			},
+			want: []trx.Erc20Transaction{
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(tok, "TRANSFER", alice, bob, "99", nil),
+			},
+		},
+		{
+			name: "two sends, one transfer",
+			list: []trx.Erc20Transaction{
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(tok, "TRANSFER", alice, bob, "100", nil),
+			},
+			want: []trx.Erc20Transaction{
+				entry(tok, "SENT", alice, bob, "100", op),
+				entry(tok, "TRANSFER", alice, bob, "100", op),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dedupErc777(tt.list)
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d entries, expected %d; %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Type != w.Type || g.Token.Address != w.Token.Address || g.Sender != w.Sender ||
+					g.Recipient != w.Recipient || g.Amount != w.Amount || (g.Erc777 == nil) != (w.Erc777 == nil) {
+					t.Errorf("entry %d is %+v, expected %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
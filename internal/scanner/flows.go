@@ -0,0 +1,82 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+// flowTypes represents the types of entries moving the value between addresses.
+// Other entries describe the same movement from a different angle (e.g. swaps, deposits, staking).
+var flowTypes = map[string]bool{
+	"TRANSFER":          true,
+	"NATIVE_TRANSFER":   true,
+	"INTERNAL_NATIVE":   true,
+	"CONTRACT_CREATION": true,
+}
+
+// flowKey represents the key of a net flow.
+type flowKey struct {
+	adr   common.Address
+	token common.Address
+}
+
+// netFlows computes the net change of token balances of addresses across all the entries of the transaction.
+// Flows are ordered by the first appearance in entries; zero flows and the zero address of mints and burns are skipped.
+// The flows of the transaction sender are marked as the user flows.
+func netFlows(tx *trx.BlockchainTransaction) []trx.Flow {
+	order := make([]flowKey, 0)
+	deltas := make(map[flowKey]*big.Int)
+	tokens := make(map[common.Address]trx.Token)
+
+	add := func(adr common.Address, tok trx.Token, v *big.Int) {
+		if adr == (common.Address{}) {
+			return
+		}
+
+		key := flowKey{adr: adr, token: tok.Address}
+		if _, ok := deltas[key]; !ok {
+			order = append(order, key)
+			deltas[key] = new(big.Int)
+		}
+		deltas[key].Add(deltas[key], v)
+		tokens[tok.Address] = tok
+	}
+
+	for _, et := range tx.Transactions {
+		if !flowTypes[et.Type] {
+			continue
+		}
+
+		v, ok := new(big.Int).SetString(et.Amount, 10)
+		if !ok || v.Sign() == 0 {
+			continue
+		}
+
+		add(et.Sender, et.Token, new(big.Int).Neg(v))
+		add(et.Recipient, et.Token, v)
+	}
+
+	flows := make([]trx.Flow, 0)
+	for _, key := range order {
+		d := deltas[key]
+		if d.Sign() == 0 {
+			continue
+		}
+
+		tok := tokens[key.token]
+		f := trx.Flow{
+			Address: key.adr,
+			User:    key.adr == tx.From,
+			Token:   key.token,
+			Symbol:  tok.Symbol,
+			Delta:   d.String(),
+		}
+		if tok.HasDecimals() {
+			f.DeltaDecimal = decimalAmount(d, int(tok.Decimals))
+		}
+		flows = append(flows, f)
+	}
+	return flows
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"testing"
+)
+
+func TestNetFlows(t *testing.T) {
+	user := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	router := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	pool := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	usdc := trx.Token{Address: common.HexToAddress("0x04068DA6C83AFCFA0e13ba15A6696662335D5B75"), Symbol: "USDC", Decimals: 6, Standard: "ERC-20"}
+	nft := trx.Token{Address: common.HexToAddress("0x4444444444444444444444444444444444444444"), Symbol: "NFT", Standard: "ERC-721"}
+
+	entry := func(typ string, tok trx.Token, from, to common.Address, amount string) trx.Erc20Transaction {
+		return trx.Erc20Transaction{Type: typ, Token: tok, Sender: from, Recipient: to, Amount: amount}
+	}
+
+	tests := []struct {
+		name string
+		list []trx.Erc20Transaction
+		want []trx.Flow
+	}{
+		{
+			name: "round trip nets out",
+			list: []trx.Erc20Transaction{
+				entry("TRANSFER", usdc, user, router, "1000000"),
+				entry("TRANSFER", usdc, router, user, "1000000"),
+			},
+			want: []trx.Flow{},
+		},
+		{
+			name: "native and token legs",
+			list: []trx.Erc20Transaction{
+				entry("NATIVE_TRANSFER", trx.NativeToken, user, pool, "1000000000000000000"),
+				entry("TRANSFER", usdc, pool, user, "2500000"),
+			},
+			want: []trx.Flow{
+				{Address: user, User: true, Token: common.Address{}, Symbol: "FTM", Delta: "-1000000000000000000", DeltaDecimal: "-1"},
+				{Address: pool, Token: common.Address{}, Symbol: "FTM", Delta: "1000000000000000000", DeltaDecimal: "1"},
+				{Address: pool, Token: usdc.Address, Symbol: "USDC", Delta: "-2500000", DeltaDecimal: "-2.5"},
+				{Address: user, User: true, Token: usdc.Address, Symbol: "USDC", Delta: "2500000", DeltaDecimal: "2.5"},
+			},
+		},
+		{
+			name: "user and router split",
+			list: []trx.Erc20Transaction{
+				entry("TRANSFER", usdc, user, router, "3000000"),
+				entry("TRANSFER", usdc, router, pool, "2990000"),
+				entry("SWAP", usdc, pool, user, "2990000"),
+			},
+			want: []trx.Flow{
+				{Address: user, User: true, Token: usdc.Address, Symbol: "USDC", Delta: "-3000000", DeltaDecimal: "-3"},
+				{Address: router, Token: usdc.Address, Symbol: "USDC", Delta: "10000", DeltaDecimal: "0.01"},
+				{Address: pool, Token: usdc.Address, Symbol: "USDC", Delta: "2990000", DeltaDecimal: "2.99"},
+			},
+		},
+		{
+			name: "zero deltas omitted",
+			list: []trx.Erc20Transaction{
+				entry("TRANSFER", usdc, user, router, "0"),
+				entry("TRANSFER", usdc, common.Address{}, user, "500000"),
+				entry("TRANSFER", usdc, router, router, "100"),
+			},
+			want: []trx.Flow{
+				{Address: user, User: true, Token: usdc.Address, Symbol: "USDC", Delta: "500000", DeltaDecimal: "0.5"},
+			},
+		},
+		{
+			name: "decimals scaled",
+			list: []trx.Erc20Transaction{
+				entry("TRANSFER", usdc, router, pool, "1"),
+				entry("TRANSFER", nft, router, pool, "42"),
+			},
+			want: []trx.Flow{
+				{Address: router, Token: usdc.Address, Symbol: "USDC", Delta: "-1", DeltaDecimal: "-0.000001"},
+				{Address: pool, Token: usdc.Address, Symbol: "USDC", Delta: "1", DeltaDecimal: "0.000001"},
+				{Address: router, Token: nft.Address, Symbol: "NFT", Delta: "-42"},
+				{Address: pool, Token: nft.Address, Symbol: "NFT", Delta: "42"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := netFlows(&trx.BlockchainTransaction{From: user, Transactions: tt.list})
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d flows, expected %d; %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("flow %d is %+v, expected %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
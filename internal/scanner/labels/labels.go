@@ -0,0 +1,117 @@
+// Package labels implements human readable labels of addresses loaded from user maintained files.
+package labels
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Labels represents a merged set of address labels.
+type Labels struct {
+	labels map[common.Address]string
+}
+
+// New loads the given label files. JSON files contain an object mapping addresses to labels,
+// other files are CSV with the address and the label on each line; lines starting with # are skipped.
+// The files are ordered by priority, the first file labelling an address wins.
+func New(files []string) (*Labels, error) {
+	l := &Labels{labels: make(map[common.Address]string)}
+
+	for _, fn := range files {
+		var list map[string]string
+		var err error
+
+		if strings.EqualFold(filepath.Ext(fn), ".json") {
+			list, err = loadJSON(fn)
+		} else {
+			list, err = loadCSV(fn)
+		}
+		if err != nil {
+			log.Println("can not load labels", fn, err.Error())
+			return nil, err
+		}
+
+		var count int
+		for adr, label := range list {
+			if !common.IsHexAddress(adr) {
+				log.Println("invalid labelled address", adr, "in", fn)
+				continue
+			}
+
+			a := common.HexToAddress(adr)
+			if _, ok := l.labels[a]; ok || label == "" {
+				continue
+			}
+			l.labels[a] = label
+			count++
+		}
+
+		log.Println("labels loaded from", fn, count, "addresses")
+	}
+	return l, nil
+}
+
+// loadJSON loads labels from a JSON file.
+func loadJSON(fn string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var list map[string]string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// loadCSV loads labels from a CSV file.
+func loadCSV(fn string) (map[string]string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	list := make(map[string]string)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rec) < 2 {
+			continue
+		}
+
+		adr := strings.TrimSpace(rec[0])
+		if _, ok := list[adr]; !ok {
+			list[adr] = strings.TrimSpace(rec[1])
+		}
+	}
+}
+
+// Label provides the label of the address, if any.
+func (l *Labels) Label(adr common.Address) string {
+	if l == nil {
+		return ""
+	}
+	return l.labels[adr]
+}
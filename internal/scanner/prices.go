@@ -0,0 +1,181 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"encoding/json"
+	"erc20pump/internal/scanner/cache"
+	"erc20pump/internal/scanner/pricetable"
+	"erc20pump/internal/scanner/rpc"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"time"
+)
+
+// priceEnricher represents an enrichment of token amounts with USD values from price oracles and price files.
+type priceEnricher struct {
+	feeds   map[common.Address]common.Address
+	prices  *pricetable.PriceTable
+	maxAge  time.Duration
+	history bool
+	rpc     *rpc.Adapter
+	cache   *cache.MemCache
+}
+
+// newPriceEnricher creates a new price enricher using the given price feeds by token address,
+// and the price table, if any, for tokens without a price feed. Prices older than the max age are ignored.
+// If history is set, the price feed is read at the block of the transfer, the latest price is used otherwise.
+func newPriceEnricher(feeds map[common.Address]common.Address, pt *pricetable.PriceTable, maxAge time.Duration, history bool, rpc *rpc.Adapter, cache *cache.MemCache) *priceEnricher {
+	return &priceEnricher{
+		feeds:   feeds,
+		prices:  pt,
+		maxAge:  maxAge,
+		history: history,
+		rpc:     rpc,
+		cache:   cache,
+	}
+}
+
+// loadPriceFeeds loads the price feeds of tokens from a JSON file mapping token addresses to feed addresses.
+// The native FTM is mapped by the zero address.
+func loadPriceFeeds(path string) (map[common.Address]common.Address, error) {
+	feeds := make(map[common.Address]common.Address)
+	if path == "" {
+		return feeds, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Println("can not read price feeds", path, err.Error())
+		return nil, err
+	}
+
+	var list map[common.Address]common.Address
+	if err := json.Unmarshal(data, &list); err != nil {
+		log.Println("can not decode price feeds", path, err.Error())
+		return nil, err
+	}
+
+	for tok, feed := range list {
+		feeds[tok] = feed
+	}
+
+	log.Println("price feeds loaded", path, len(feeds), "tokens")
+	return feeds, nil
+}
+
+// price adds the USD price of the token and the USD value of the amount to the transaction,
+// if the decimal amount is known. The price feed of the token is preferred, the price table is used
+// if there is no feed or the feed price is not usable at the block.
+func (pe *priceEnricher) price(et *trx.Erc20Transaction, blk uint64) {
+	if et.AmountDecimal == "" {
+		return
+	}
+
+	if p := pe.oracle(et, blk); p != nil {
+		et.Price = p
+		return
+	}
+	et.Price = pe.table(et, blk)
+}
+
+// oracle provides the price of the token from its price feed, if any. Prices older than the staleness limit
+// are not used, neither is the latest price updated after the block if the historical price is not available.
+func (pe *priceEnricher) oracle(et *trx.Erc20Transaction, blk uint64) *trx.Price {
+	feed, ok := pe.feeds[et.Token.Address]
+	if !ok {
+		return nil
+	}
+
+	at := blk
+	if !pe.history {
+		at = 0
+	}
+
+	r, err := pe.cache.Round(feed, at, pe.rpc.RoundAt)
+	if err != nil {
+		log.Println("price not available", feed.String(), at, err.Error())
+		return nil
+	}
+
+	if r.Answer.Sign() <= 0 {
+		log.Println("invalid price", feed.String(), r.RoundID.String(), r.Answer.String())
+		return nil
+	}
+
+	ts := pe.blockTime(blk)
+	var age int64
+	if ts != 0 {
+		age = int64(ts) - int64(r.UpdatedAt)
+	}
+
+	// the latest round is not the price in effect at an older block
+	if !pe.history && (ts == 0 || age < 0) {
+		return nil
+	}
+	if pe.maxAge > 0 && age > int64(pe.maxAge.Seconds()) {
+		log.Println("stale price", feed.String(), r.RoundID.String(), age, "seconds old")
+		return nil
+	}
+
+	return &trx.Price{
+		Source:    trx.PriceSourceOracle,
+		Feed:      &feed,
+		RoundID:   r.RoundID.String(),
+		Price:     decimalAmount(r.Answer, int(r.Decimals)),
+		UpdatedAt: r.UpdatedAt,
+		Age:       age,
+		ValueUSD:  usdValue(et.Amount, int(et.Token.Decimals), r.Answer, int(r.Decimals)),
+	}
+}
+
+// table provides the latest price of the token at or before the block time from the price table, if any.
+// Prices older than the staleness limit are not used.
+func (pe *priceEnricher) table(et *trx.Erc20Transaction, blk uint64) *trx.Price {
+	if pe.prices == nil {
+		return nil
+	}
+
+	ts := pe.blockTime(blk)
+	p, ok := pe.prices.Lookup(et.Token.Address, ts)
+	if !ok {
+		return nil
+	}
+
+	age := ts - p.Time
+	if pe.maxAge > 0 && age > uint64(pe.maxAge.Seconds()) {
+		return nil
+	}
+
+	return &trx.Price{
+		Source:    trx.PriceSourceFile,
+		Price:     decimalAmount(p.Price, p.Scale),
+		UpdatedAt: p.Time,
+		Age:       int64(age),
+		ValueUSD:  usdValue(et.Amount, int(et.Token.Decimals), p.Price, p.Scale),
+	}
+}
+
+// blockTime provides the time of the block, zero if not available.
+func (pe *priceEnricher) blockTime(blk uint64) uint64 {
+	ts, err := pe.cache.BlockTime(blk, pe.rpc.BlockTime)
+	if err != nil {
+		log.Println("block time not available", blk, err.Error())
+		return 0
+	}
+	return ts
+}
+
+// usdValue provides the exact USD value of the raw amount of a token with the given decimals
+// at the price with the given decimals.
+func usdValue(amount string, decimals int, price *big.Int, priceDecimals int) string {
+	raw, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return ""
+	}
+
+	// the value is the raw amount times the raw price, scaled by both the decimals
+	return decimalAmount(new(big.Int).Mul(raw, price), decimals+priceDecimals)
+}
@@ -0,0 +1,221 @@
+// Package pricetable implements historical USD prices of tokens loaded from local price files.
+package pricetable
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxExponent represents the largest absolute exponent of a price in the exponent form.
+const maxExponent = 64
+
+// Point represents a price of a token at a time.
+type Point struct {
+	Time  uint64
+	Price *big.Int
+	Scale int
+}
+
+// PriceTable represents historical prices of tokens ordered by time.
+type PriceTable struct {
+	prices map[common.Address][]Point
+}
+
+// record represents a price record of a JSON price file.
+type record struct {
+	Token     common.Address  `json:"token"`
+	Timestamp json.RawMessage `json:"timestamp"`
+	Price     json.Number     `json:"price"`
+}
+
+// New loads the given price files. JSON files contain an array of records with the token, the timestamp
+// and the price, other files are CSV with the same columns on each line; lines starting with # are skipped.
+// Timestamps are UNIX seconds or ISO-8601 times, prices are decimal numbers in USD, optionally in the exponent form.
+func New(files []string) (*PriceTable, error) {
+	pt := &PriceTable{prices: make(map[common.Address][]Point)}
+
+	for _, fn := range files {
+		var err error
+		var count int
+
+		if strings.EqualFold(filepath.Ext(fn), ".json") {
+			count, err = pt.loadJSON(fn)
+		} else {
+			count, err = pt.loadCSV(fn)
+		}
+		if err != nil {
+			log.Println("can not load prices", fn, err.Error())
+			return nil, err
+		}
+
+		log.Println("prices loaded from", fn, count, "records")
+	}
+
+	for _, list := range pt.prices {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Time < list[j].Time
+		})
+	}
+	return pt, nil
+}
+
+// loadJSON loads prices from a JSON file.
+func (pt *PriceTable) loadJSON(fn string) (int, error) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return 0, err
+	}
+
+	var list []record
+	if err := json.Unmarshal(data, &list); err != nil {
+		return 0, err
+	}
+
+	for i, r := range list {
+		ts := strings.Trim(string(r.Timestamp), `"`)
+		if err := pt.add(r.Token.Hex(), ts, r.Price.String()); err != nil {
+			return 0, fmt.Errorf("record %d; %w", i, err)
+		}
+	}
+	return len(list), nil
+}
+
+// loadCSV loads prices from a CSV file; a header line is skipped.
+func (pt *PriceTable) loadCSV(fn string) (int, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = 3
+	r.TrimLeadingSpace = true
+
+	var count int
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if line == 1 && !common.IsHexAddress(strings.TrimSpace(rec[0])) {
+			continue
+		}
+		if err := pt.add(rec[0], rec[1], rec[2]); err != nil {
+			return 0, fmt.Errorf("line %d; %w", line, err)
+		}
+		count++
+	}
+}
+
+// add parses and adds a price record.
+func (pt *PriceTable) add(token string, ts string, price string) error {
+	token, ts, price = strings.TrimSpace(token), strings.TrimSpace(ts), strings.TrimSpace(price)
+	if !common.IsHexAddress(token) {
+		return fmt.Errorf("invalid token address %s", token)
+	}
+
+	t, err := parseTime(ts)
+	if err != nil {
+		return err
+	}
+
+	p, scale, err := parseDecimal(price)
+	if err != nil {
+		return err
+	}
+
+	adr := common.HexToAddress(token)
+	pt.prices[adr] = append(pt.prices[adr], Point{Time: t, Price: p, Scale: scale})
+	return nil
+}
+
+// parseTime parses UNIX seconds or ISO-8601 time.
+func parseTime(ts string) (uint64, error) {
+	if v, err := strconv.ParseUint(ts, 10, 64); err == nil {
+		return v, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %s", ts)
+	}
+	return uint64(t.Unix()), nil
+}
+
+// parseDecimal parses a non-negative decimal number into an integer and the number of decimal places.
+// The exponent form used by spreadsheets and price APIs for tiny prices, e.g. 1.5e-6, is accepted as well.
+func parseDecimal(s string) (*big.Int, int, error) {
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		mantissa = s[:i]
+		exp, err = strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxExponent || exp < -maxExponent {
+			return nil, 0, fmt.Errorf("invalid price %s", s)
+		}
+	}
+
+	v, scale, err := parsePlainDecimal(mantissa)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid price %s", s)
+	}
+
+	scale -= exp
+	if scale < 0 {
+		v.Mul(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+		scale = 0
+	}
+	return v, scale, nil
+}
+
+// parsePlainDecimal parses a non-negative decimal number without exponent into an integer and the number of decimal places.
+func parsePlainDecimal(s string) (*big.Int, int, error) {
+	parts := strings.SplitN(s, ".", 2)
+
+	digits := parts[0]
+	if len(parts) == 2 {
+		digits += parts[1]
+	}
+
+	v, ok := new(big.Int).SetString(digits, 10)
+	if !ok || v.Sign() < 0 || strings.ContainsAny(digits, "+-") {
+		return nil, 0, fmt.Errorf("invalid price %s", s)
+	}
+	return v, len(digits) - len(parts[0]), nil
+}
+
+// Lookup provides the latest price of the token at or before the given time.
+func (pt *PriceTable) Lookup(token common.Address, ts uint64) (Point, bool) {
+	if pt == nil {
+		return Point{}, false
+	}
+
+	list := pt.prices[token]
+	i := sort.Search(len(list), func(i int) bool {
+		return list[i].Time > ts
+	})
+	if i == 0 {
+		return Point{}, false
+	}
+	return list[i-1], true
+}
@@ -0,0 +1,173 @@
+package pricetable
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		price string
+		scale int
+		fails bool
+	}{
+		{"integer", "42", "42", 0, false},
+		{"decimal", "1.25", "125", 2, false},
+		{"leading dot", ".5", "5", 1, false},
+		{"trailing dot", "3.", "3", 0, false},
+		{"tiny", "0.000001", "1", 6, false},
+		{"zero", "0", "0", 0, false},
+		{"exponent", "1e-6", "1", 6, false},
+		{"decimal exponent", "1.5e-6", "15", 7, false},
+		{"upper case exponent", "2.5E-3", "25", 4, false},
+		{"positive exponent", "1.5e3", "1500", 0, false},
+		{"explicit positive exponent", "1.25e+1", "125", 1, false},
+		{"zero exponent", "7e0", "7", 0, false},
+		{"empty", "", "", 0, true},
+		{"negative", "-1.5", "", 0, true},
+		{"plus sign", "+1.5", "", 0, true},
+		{"two dots", "1.2.3", "", 0, true},
+		{"letters", "abc", "", 0, true},
+		{"missing exponent", "1e", "", 0, true},
+		{"missing mantissa", "e5", "", 0, true},
+		{"negative mantissa with exponent", "-1e-6", "", 0, true},
+		{"huge exponent", "1e1000000", "", 0, true},
+		{"tiny exponent", "1e-1000000", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, scale, err := parseDecimal(tt.s)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("invalid price %q parsed as %s with scale %d", tt.s, v.String(), scale)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != tt.price || scale != tt.scale {
+				t.Errorf("parsed %s with scale %d, expected %s with scale %d", v.String(), scale, tt.price, tt.scale)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name  string
+		ts    string
+		want  uint64
+		fails bool
+	}{
+		{"unix seconds", "1700000000", 1700000000, false},
+		{"zero", "0", 0, false},
+		{"iso-8601 utc", "2023-11-14T22:13:20Z", 1700000000, false},
+		{"iso-8601 offset", "2023-11-15T00:13:20+02:00", 1700000000, false},
+		{"date only", "2023-11-14", 0, true},
+		{"negative", "-1", 0, true},
+		{"decimal seconds", "1700000000.5", 0, true},
+		{"empty", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTime(tt.ts)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("invalid timestamp %q parsed as %d", tt.ts, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parsed %d, expected %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	usdc := common.HexToAddress("0x04068DA6C83AFCFA0e13ba15A6696662335D5B75")
+	wftm := common.HexToAddress("0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83")
+
+	dir := t.TempDir()
+	csv := filepath.Join(dir, "prices.csv")
+	if err := ioutil.WriteFile(csv, []byte("token,timestamp,price\n"+
+		"# stable coin\n"+
+		usdc.Hex()+",3000,1.01\n"+
+		usdc.Hex()+",1000,0.99\n"+
+		usdc.Hex()+",2023-11-14T22:13:20Z,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	js := filepath.Join(dir, "prices.json")
+	if err := ioutil.WriteFile(js, []byte(`[
+		{"token":"`+wftm.Hex()+`","timestamp":2000,"price":2.5e-1},
+		{"token":"`+wftm.Hex()+`","timestamp":"1970-01-01T00:33:21Z","price":"0.3"}
+	]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pt, err := New([]string{csv, js})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token common.Address
+		ts    uint64
+		found bool
+		price string
+		scale int
+	}{
+		{"before the first price", usdc, 999, false, "", 0},
+		{"at the first price", usdc, 1000, true, "99", 2},
+		{"between prices", usdc, 2999, true, "99", 2},
+		{"at a price", usdc, 3000, true, "101", 2},
+		{"nearest earlier of unordered file", usdc, 1699999999, true, "101", 2},
+		{"after the last price", usdc, 1800000000, true, "1", 0},
+		{"exponent form", wftm, 2000, true, "25", 2},
+		{"iso-8601 time", wftm, 2001, true, "3", 1},
+		{"unknown token", common.HexToAddress("0x01"), 3000, false, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := pt.Lookup(tt.token, tt.ts)
+			if ok != tt.found {
+				t.Fatalf("price found %v, expected %v", ok, tt.found)
+			}
+			if ok && (p.Price.String() != tt.price || p.Scale != tt.scale) {
+				t.Errorf("price %s with scale %d, expected %s with scale %d", p.Price.String(), p.Scale, tt.price, tt.scale)
+			}
+		})
+	}
+
+	var empty *PriceTable
+	if _, ok := empty.Lookup(usdc, 3000); ok {
+		t.Error("price found in an empty table")
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.csv")
+	if err := ioutil.WriteFile(bad, []byte("0x04068DA6C83AFCFA0e13ba15A6696662335D5B75,1000,cheap\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New([]string{bad}); err == nil {
+		t.Error("invalid price accepted")
+	}
+	if _, err := New([]string{filepath.Join(dir, "missing.csv")}); err == nil {
+		t.Error("missing file accepted")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"log"
+	"sort"
 	"sync"
 	"time"
 )
@@ -28,15 +29,18 @@ type logPuller struct {
 	wg            *sync.WaitGroup
 	rpc           *rpc.Adapter
 	cache         *cache.MemCache
+	tokens        *tokenResolver
 	topics        [][]common.Hash
+	contract      common.Address
+	nativeTrx     bool
 	contractMatch func(rc *common.Address) bool
 }
 
 // newPuller creates a new puller service.
-func newPuller(cfg *cfg.Config, rpc *rpc.Adapter, cache *cache.MemCache) *logPuller {
+func newPuller(cfg *cfg.Config, dec map[common.Hash]EventDecoder, tokens *tokenResolver, rpc *rpc.Adapter, cache *cache.MemCache) *logPuller {
 	// build a list of topics we want to scan for
-	topics := [][]common.Hash{make([]common.Hash, 0, len(LogTopicProcessor))}
-	for t := range LogTopicProcessor {
+	topics := [][]common.Hash{make([]common.Hash, 0, len(dec))}
+	for t := range dec {
 		topics[0] = append(topics[0], t)
 	}
 
@@ -47,8 +51,11 @@ func newPuller(cfg *cfg.Config, rpc *rpc.Adapter, cache *cache.MemCache) *logPul
 		currentBlock: cfg.StartBlock,
 		sigStop:      make(chan bool, 1),
 		topics:       topics,
+		contract:     cfg.ScanContract,
+		nativeTrx:    cfg.NativeTransactions,
 		rpc:          rpc,
 		cache:        cache,
+		tokens:       tokens,
 		contractMatch: func(rc *common.Address) bool {
 			return bytes.Compare(rc.Bytes(), cfg.ScanContract.Bytes()) == 0
 		},
@@ -138,22 +145,135 @@ func (lp *logPuller) nextLogs() []types.Log {
 		return nil
 	}
 
+	if lp.nativeTrx {
+		logs = lp.valueTransactions(logs, lp.currentBlock, target)
+	}
+
 	// advance current block
 	lp.currentBlock = target + 1
+
+	lp.prefetchTrx(logs)
+	lp.prefetch(logs)
+	return logs
+}
+
+// valueTransactions adds transactions sending native value to the watched contract without any logs of interest
+// in the given blocks range to the logs. These transactions are represented by a log record without topics.
+func (lp *logPuller) valueTransactions(logs []types.Log, from uint64, to uint64) []types.Log {
+	seen := make(map[common.Hash]bool)
+	for _, ev := range logs {
+		seen[ev.TxHash] = true
+	}
+
+	for blk := from; blk <= to; blk++ {
+		list, err := lp.rpc.ValueTransactions(blk, lp.contract)
+		if err != nil {
+			log.Println("value transactions not available in block", blk, err.Error())
+			continue
+		}
+
+		for idx, hash := range list {
+			if seen[hash] {
+				continue
+			}
+			logs = append(logs, types.Log{
+				Address:     lp.contract,
+				BlockNumber: blk,
+				TxHash:      hash,
+				TxIndex:     idx,
+			})
+		}
+	}
+
+	// keep the logs of the same transaction together
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].TxIndex < logs[j].TxIndex
+	})
 	return logs
 }
 
+// prefetchTrx loads recipients of the transactions of the given logs in batches, and execution details
+// of the matching transactions, so the recipients and receipts don't need to be loaded one by one.
+// Receipts of the other transactions are never loaded. The logs are still pulled by eth_getLogs,
+// since the logs of interest are needed to find the matching transactions in the first place.
+func (lp *logPuller) prefetchTrx(logs []types.Log) {
+	seen := make(map[common.Hash]bool)
+	hashes := make([]common.Hash, 0)
+	for _, ev := range logs {
+		if seen[ev.TxHash] || lp.cache.HasTrxDetail(ev.TxHash) {
+			continue
+		}
+
+		seen[ev.TxHash] = true
+		hashes = append(hashes, ev.TxHash)
+	}
+
+	recipients := lp.rpc.TrxRecipients(hashes)
+	for h, to := range recipients {
+		if to != nil {
+			lp.cache.SetTrxRecipient(h, *to)
+		}
+	}
+
+	// contract deployments need the receipt to know the created contract
+	queued := make(map[common.Hash]bool)
+	list := make([]common.Hash, 0)
+	for _, ev := range logs {
+		to, ok := recipients[ev.TxHash]
+		if !ok || queued[ev.TxHash] || (to != nil && !lp.matches(&ev, *to)) {
+			continue
+		}
+
+		queued[ev.TxHash] = true
+		list = append(list, ev.TxHash)
+	}
+
+	for _, td := range lp.rpc.TrxDetails(list) {
+		if td.Err != nil {
+			log.Println("transaction detail not available", td.Hash.String(), td.Err.Error())
+			continue
+		}
+		lp.cache.SetTrxDetail(td)
+	}
+}
+
+// prefetch resolves tokens transferred by the matching transactions of the given logs in advance,
+// so new tokens of the whole window are resolved in a single batch instead of one by one.
+func (lp *logPuller) prefetch(logs []types.Log) {
+	list := make(map[common.Address]uint64)
+	for _, ev := range logs {
+		if len(ev.Topics) == 0 || ev.Topics[0] != erc20TransferTopic {
+			continue
+		}
+
+		if _, ok := list[ev.Address]; ok {
+			continue
+		}
+
+		rec, err := lp.cache.TrxRecipient(ev.TxHash, lp.rpc.TrxRecipient)
+		if err != nil || !lp.contractMatch(&rec) {
+			continue
+		}
+		list[ev.Address] = ev.BlockNumber
+	}
+
+	lp.tokens.prefetch(list)
+}
+
 // process given event log record.
 func (lp *logPuller) process(ev types.Log) {
 	// do we know the transaction recipient?
 	rec, err := lp.cache.TrxRecipient(ev.TxHash, lp.rpc.TrxRecipient)
 	if err != nil {
-		log.Println("recipient not available", err.Error())
+		fatalf(lp.tokens.registry, "recipient not available for %s; %s", ev.TxHash.String(), err.Error())
 		return
 	}
 
 	// is the recipient interesting?
-	if !lp.contractMatch(&rec) {
+	if !lp.matches(&ev, rec) {
 		return
 	}
 
@@ -162,3 +282,12 @@ func (lp *logPuller) process(ev types.Log) {
 	// this one is what we're looking for
 	lp.output <- ev
 }
+
+// matches checks if the event of a transaction sent to the given recipient is of interest.
+// Events are matched by the transaction recipient, administrative events also by the contract emitting them.
+func (lp *logPuller) matches(ev *types.Log, rc common.Address) bool {
+	if lp.contractMatch(&rc) {
+		return true
+	}
+	return len(ev.Topics) > 0 && adminTopics[ev.Topics[0]] && lp.contractMatch(&ev.Address)
+}
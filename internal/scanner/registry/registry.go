@@ -0,0 +1,173 @@
+// Package registry implements a persistent store of token metadata shared across restarts.
+package registry
+
+import (
+	"encoding/json"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Record represents a token metadata record in the registry.
+type Record struct {
+	Token     trx.Token `json:"token"`
+	FirstSeen uint64    `json:"firstSeen"`
+	Refreshed time.Time `json:"refreshed"`
+	Failed    bool      `json:"failed,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Registry represents a persistent store of token metadata records keyed by the token address.
+type Registry struct {
+	mu     sync.RWMutex
+	path   string
+	dirty  bool
+	tokens map[common.Address]*Record
+}
+
+// New creates a new token registry backed by the given file and preloads the records stored in it.
+// The registry is kept in memory only if the path is empty.
+func New(path string) (*Registry, error) {
+	reg := &Registry{
+		path:   path,
+		tokens: make(map[common.Address]*Record),
+	}
+
+	if path == "" {
+		return reg, nil
+	}
+
+	// missing file is fine, we start with an empty registry
+	err := reg.Import(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	reg.dirty = false
+	return reg, nil
+}
+
+// Get provides the token record by the token address.
+func (r *Registry) Get(adr common.Address) (Record, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, ok := r.tokens[adr]
+	if !ok {
+		return Record{}, false
+	}
+	return *rec, true
+}
+
+// Put stores the token record in the registry, the first seen block of a known token is kept.
+func (r *Registry) Put(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, ok := r.tokens[rec.Token.Address]; ok && old.FirstSeen != 0 && old.FirstSeen < rec.FirstSeen {
+		rec.FirstSeen = old.FirstSeen
+	}
+
+	r.tokens[rec.Token.Address] = &rec
+	r.dirty = true
+}
+
+// List provides all the token records of the registry.
+func (r *Registry) List() []Record {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]Record, 0, len(r.tokens))
+	for _, rec := range r.tokens {
+		list = append(list, *rec)
+	}
+	return list
+}
+
+// Flush writes the registry into the backing file, if changed since the last flush.
+func (r *Registry) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.path == "" || !r.dirty {
+		return
+	}
+
+	if err := r.write(r.path); err != nil {
+		log.Println("can not store token registry", err.Error())
+		return
+	}
+	r.dirty = false
+}
+
+// Export writes all the token records into the given file.
+func (r *Registry) Export(path string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.write(path)
+}
+
+// Import merges token records from the given file into the registry.
+// Successfully resolved records of the file replace the failed ones of the registry, never the other way around.
+func (r *Registry) Import(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var list []Record
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range list {
+		old, ok := r.tokens[list[i].Token.Address]
+		if ok && !old.Failed && list[i].Failed {
+			continue
+		}
+		r.tokens[list[i].Token.Address] = &list[i]
+	}
+
+	r.dirty = true
+	log.Println("token registry loaded", path, len(list), "tokens")
+	return nil
+}
+
+// write stores the records into the given file, the file is replaced atomically.
+func (r *Registry) write(path string) error {
+	list := make([]*Record, 0, len(r.tokens))
+	for _, rec := range r.tokens {
+		list = append(list, rec)
+	}
+
+	data, err := json.MarshalIndent(list, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,263 @@
+// Package reputation implements detection of spam and scam tokens.
+package reputation
+
+import (
+	"bufio"
+	"erc20pump/internal/scanner/registry"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Flags of suspicious tokens.
+const (
+	FlagDenied        = "DENIED"
+	FlagImpersonation = "IMPERSONATION"
+	FlagAbsurdSupply  = "ABSURD_SUPPLY"
+	FlagNonStandard   = "NON_STANDARD"
+	FlagZeroBurst     = "ZERO_VALUE_BURST"
+)
+
+const (
+	// zeroBurstWindow represents the number of blocks zero value transfers of a token are counted in.
+	zeroBurstWindow = 1000
+
+	// zeroBurstLimit represents the number of zero value transfers in the window considered a burst.
+	zeroBurstLimit = 20
+)
+
+// valueTypes represents the types of entries moving value of the token emitting them. Other entries, like swaps
+// of a pool or administrative changes of a governor, are emitted by contracts not expected to be tokens.
+var valueTypes = map[string]bool{
+	"TRANSFER": true,
+	"APPROVAL": true,
+	"MINT":     true,
+	"BURN":     true,
+}
+
+// maxSupply represents the largest total supply in whole tokens considered sane.
+var maxSupply = new(big.Int).Exp(big.NewInt(10), big.NewInt(15), nil)
+
+// confusables maps characters commonly used to impersonate token symbols to their latin look-alikes.
+var confusables = map[rune]rune{
+	'А': 'A', 'В': 'B', 'С': 'C', 'Е': 'E', 'Н': 'H', 'І': 'I', 'Ј': 'J', 'К': 'K', 'М': 'M',
+	'О': 'O', 'Р': 'P', 'Ѕ': 'S', 'Т': 'T', 'Х': 'X', 'У': 'Y', 'Ԁ': 'D', 'Ԛ': 'Q', 'Ԝ': 'W',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N',
+	'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+}
+
+// burst represents zero value transfers of a token counted in the current window.
+type burst struct {
+	start   uint64
+	count   int
+	flagged bool
+}
+
+// Reputation represents a detector of spam and scam tokens.
+type Reputation struct {
+	mu     sync.Mutex
+	deny   map[common.Address]bool
+	owners map[string]map[common.Address]bool
+	zero   map[common.Address]*burst
+}
+
+// New creates a new token reputation detector. The listed tokens and the verified tokens of the registry
+// are the legitimate owners of their symbols; a symbol is never owned by the token seen first with it,
+// since a scam token may show up before the real one. The deny list file contains one token address per line, it's ignored if the path is empty.
+func New(denyFile string, listed map[common.Address]string, known []registry.Record) (*Reputation, error) {
+	r := &Reputation{
+		deny:   make(map[common.Address]bool),
+		owners: make(map[string]map[common.Address]bool),
+		zero:   make(map[common.Address]*burst),
+	}
+
+	if denyFile != "" {
+		if err := r.loadDenyList(denyFile); err != nil {
+			log.Println("can not load token deny list", denyFile, err.Error())
+			return nil, err
+		}
+	}
+
+	for adr, sym := range listed {
+		r.own(normalize(sym), adr)
+	}
+
+	for _, rec := range known {
+		if rec.Token.Verified && !rec.Failed && !r.deny[rec.Token.Address] {
+			r.own(normalize(rec.Token.Symbol), rec.Token.Address)
+		}
+	}
+	return r, nil
+}
+
+// loadDenyList loads denied token addresses from the given file; empty lines and # comments are skipped.
+func (r *Reputation) loadDenyList(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.SplitN(sc.Text(), "#", 2)[0])
+		if line == "" {
+			continue
+		}
+		if !common.IsHexAddress(line) {
+			log.Println("invalid address in token deny list", line)
+			continue
+		}
+		r.deny[common.HexToAddress(line)] = true
+	}
+
+	log.Println("token deny list loaded", path, len(r.deny), "tokens")
+	return sc.Err()
+}
+
+// Apply flags the token, including the underlying tokens, if it's considered suspicious.
+func (r *Reputation) Apply(tok trx.Token) trx.Token {
+	if r == nil {
+		return tok
+	}
+
+	if tok.Pair != nil {
+		tok.Pair = &trx.TokenPair{
+			Token0: r.Apply(tok.Pair.Token0),
+			Token1: r.Apply(tok.Pair.Token1),
+		}
+	}
+
+	if tok.Asset != nil {
+		asset := r.Apply(*tok.Asset)
+		tok.Asset = &asset
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tok.Flags = r.flags(tok)
+	tok.Flagged = len(tok.Flags) > 0
+
+	// verified tokens own their symbols
+	if tok.Verified && !tok.Flagged {
+		r.own(normalize(tok.Symbol), tok.Address)
+	}
+	return tok
+}
+
+// Entry flags the token of a value moving entry as non-standard if it does not implement any known token standard.
+func (r *Reputation) Entry(et trx.Erc20Transaction) trx.Erc20Transaction {
+	if r == nil || !ValueEntry(et) || et.Token.Verified || et.Token.Standard != "" {
+		return et
+	}
+
+	et.Token.Flags = append(et.Token.Flags[:len(et.Token.Flags):len(et.Token.Flags)], FlagNonStandard)
+	et.Token.Flagged = true
+	return et
+}
+
+// ValueEntry checks if the entry moves value of the token emitting it, so it can be suppressed if the token is flagged.
+func ValueEntry(et trx.Erc20Transaction) bool {
+	return et.Admin == nil && valueTypes[et.Type]
+}
+
+// ZeroTransfer registers a zero value transfer of the token at the given block.
+// Tokens with bursts of zero value transfers are typically used for address poisoning.
+func (r *Reputation) ZeroTransfer(adr common.Address, blk uint64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.zero[adr]
+	if !ok || blk >= b.start+zeroBurstWindow {
+		if ok && b.flagged {
+			return
+		}
+		b = &burst{start: blk}
+		r.zero[adr] = b
+	}
+
+	b.count++
+	if !b.flagged && b.count >= zeroBurstLimit {
+		log.Println("zero value transfers burst detected", adr.String(), b.count, "transfers since block", b.start)
+		b.flagged = true
+	}
+}
+
+// flags provides the list of reasons the token is considered suspicious.
+func (r *Reputation) flags(tok trx.Token) []string {
+	var list []string
+	if r.deny[tok.Address] {
+		list = append(list, FlagDenied)
+	}
+
+	// listed tokens are trusted, unless explicitly denied
+	if tok.Verified {
+		return list
+	}
+
+	if own, ok := r.owners[normalize(tok.Symbol)]; ok && !own[tok.Address] {
+		list = append(list, FlagImpersonation)
+	}
+	if absurdSupply(tok) {
+		list = append(list, FlagAbsurdSupply)
+	}
+	if b, ok := r.zero[tok.Address]; ok && b.flagged {
+		list = append(list, FlagZeroBurst)
+	}
+	return list
+}
+
+// own registers the token as a legitimate owner of the normalized symbol.
+func (r *Reputation) own(sym string, adr common.Address) {
+	if sym == "" {
+		return
+	}
+	if _, ok := r.owners[sym]; !ok {
+		r.owners[sym] = make(map[common.Address]bool)
+	}
+	r.owners[sym][adr] = true
+}
+
+// absurdSupply checks if the total supply of the token exceeds any sane amount of whole tokens.
+func absurdSupply(tok trx.Token) bool {
+	supply, ok := new(big.Int).SetString(tok.TotalSupply, 10)
+	if !ok {
+		return false
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(tok.Decimals)), nil)
+	return new(big.Int).Quo(supply, unit).Cmp(maxSupply) > 0
+}
+
+// normalize provides the canonical form of a token symbol used to detect impersonation.
+// Letters are upper-cased, look-alike characters replaced and anything else than letters and digits dropped.
+func normalize(sym string) string {
+	var sb strings.Builder
+	for _, c := range sym {
+		// full width forms of ASCII characters
+		if c >= 0xFF01 && c <= 0xFF5E {
+			c -= 0xFEE0
+		}
+
+		c = unicode.ToUpper(c)
+		if l, ok := confusables[c]; ok {
+			c = l
+		}
+		if c <= unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
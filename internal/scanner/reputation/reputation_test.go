@@ -0,0 +1,154 @@
+package reputation
+
+import (
+	"erc20pump/internal/scanner/registry"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	usdc  = common.HexToAddress("0x04068DA6C83AFCFA0e13ba15A6696662335D5B75")
+	fake  = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	vault = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		sym  string
+		want string
+	}{
+		{"plain", "USDC", "USDC"},
+		{"lower case", "usdc", "USDC"},
+		{"mixed case", "wFtm", "WFTM"},
+		{"punctuation", "U.S.D.C", "USDC"},
+		{"spaces", " USD C ", "USDC"},
+		{"digits", "1inch", "1INCH"},
+		{"cyrillic look-alikes", "UЅDС", "USDC"},
+		{"greek look-alikes", "ΜΚR", "MKR"},
+		{"full width", "ＵＳＤＣ", "USDC"},
+		{"full width lower case", "ｕｓｄｃ", "USDC"},
+		{"emoji dropped", "USDC🚀", "USDC"},
+		{"other scripts dropped", "USDC币", "USDC"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalize(tt.sym); got != tt.want {
+				t.Errorf("normalize(%q) = %q, expected %q", tt.sym, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbsurdSupply(t *testing.T) {
+	tests := []struct {
+		name     string
+		supply   string
+		decimals uint8
+		want     bool
+	}{
+		{"unknown supply", "", 18, false},
+		{"invalid supply", "lots", 18, false},
+		{"sane supply", "1000000" + strings.Repeat("0", 18), 18, false},
+		{"at threshold", "1" + strings.Repeat("0", 15+18), 18, false},
+		{"just below next whole token", "1" + strings.Repeat("0", 15) + strings.Repeat("9", 18), 18, false},
+		{"above threshold", "1" + strings.Repeat("0", 14) + "1" + strings.Repeat("0", 18), 18, true},
+		{"no decimals at threshold", "1" + strings.Repeat("0", 15), 0, false},
+		{"no decimals above threshold", "1" + strings.Repeat("0", 14) + "1", 0, true},
+		{"huge raw supply of many decimals", "1" + strings.Repeat("0", 30), 18, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok := trx.Token{TotalSupply: tt.supply, Decimals: tt.decimals}
+			if got := absurdSupply(tok); got != tt.want {
+				t.Errorf("absurdSupply(%s, %d) = %v, expected %v", tt.supply, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrustedTokens(t *testing.T) {
+	deny := filepath.Join(t.TempDir(), "deny.txt")
+	if err := ioutil.WriteFile(deny, []byte("# scams\n"+fake.Hex()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	known := []registry.Record{{Token: trx.Token{Address: vault, Symbol: "fVAULT", Verified: true}}}
+	r, err := New(deny, map[common.Address]string{usdc: "USDC"}, known)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	absurd := "1" + strings.Repeat("0", 40)
+	tests := []struct {
+		name  string
+		tok   trx.Token
+		flags []string
+	}{
+		{"listed token", trx.Token{Address: usdc, Symbol: "USDC", TotalSupply: absurd, Verified: true}, nil},
+		{"verified token of the registry", trx.Token{Address: vault, Symbol: "fVAULT", TotalSupply: absurd, Verified: true}, nil},
+		{"verified token of another symbol owner", trx.Token{Address: vault, Symbol: "usdc", Verified: true}, nil},
+		{"denied verified token", trx.Token{Address: fake, Symbol: "FAKE", Verified: true}, []string{FlagDenied}},
+		{"impersonation", trx.Token{Address: common.HexToAddress("0x03"), Symbol: "UЅDС", Standard: "ERC-20"}, []string{FlagImpersonation}},
+		{"absurd supply", trx.Token{Address: common.HexToAddress("0x04"), Symbol: "SCAM", TotalSupply: absurd, Standard: "ERC-20"}, []string{FlagAbsurdSupply}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok := r.Apply(tt.tok)
+			if tok.Flagged != (len(tt.flags) > 0) || strings.Join(tok.Flags, ",") != strings.Join(tt.flags, ",") {
+				t.Errorf("token flagged %v with %v, expected %v", tok.Flagged, tok.Flags, tt.flags)
+			}
+
+			et := r.Entry(trx.Erc20Transaction{Type: "TRANSFER", Token: tok})
+			if et.Token.Flagged != tok.Flagged {
+				t.Errorf("transfer of the token flagged %v with %v", et.Token.Flagged, et.Token.Flags)
+			}
+		})
+	}
+}
+
+func TestEntry(t *testing.T) {
+	r, err := New("", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := trx.Token{Address: common.HexToAddress("0x05")}
+	tests := []struct {
+		name  string
+		et    trx.Erc20Transaction
+		value bool
+		flags []string
+	}{
+		{"transfer of non-standard token", trx.Erc20Transaction{Type: "TRANSFER", Token: pool}, true, []string{FlagNonStandard}},
+		{"approval of non-standard token", trx.Erc20Transaction{Type: "APPROVAL", Token: pool}, true, []string{FlagNonStandard}},
+		{"transfer of standard token", trx.Erc20Transaction{Type: "TRANSFER", Token: trx.Token{Standard: "ERC-20"}}, true, nil},
+		{"transfer of listed token", trx.Erc20Transaction{Type: "TRANSFER", Token: trx.Token{Verified: true}}, true, nil},
+		{"swap of a pool", trx.Erc20Transaction{Type: "SWAP", Token: pool}, false, nil},
+		{"admin change of a governor", trx.Erc20Transaction{Type: "OWNERSHIP_TRANSFERRED", Token: pool, Admin: &trx.Admin{}}, false, nil},
+		{"decoded event", trx.Erc20Transaction{Type: "EVENT", Token: pool}, false, nil},
+		{"other flags kept", trx.Erc20Transaction{Type: "BURN", Token: trx.Token{Flagged: true, Flags: []string{FlagAbsurdSupply}}},
+			true, []string{FlagAbsurdSupply, FlagNonStandard}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ValueEntry(tt.et) != tt.value {
+				t.Errorf("value entry %v, expected %v", !tt.value, tt.value)
+			}
+
+			et := r.Entry(tt.et)
+			if et.Token.Flagged != (len(tt.flags) > 0) || strings.Join(et.Token.Flags, ",") != strings.Join(tt.flags, ",") {
+				t.Errorf("token flagged %v with %v, expected %v", et.Token.Flagged, et.Token.Flags, tt.flags)
+			}
+		})
+	}
+}
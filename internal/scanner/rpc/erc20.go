@@ -2,78 +2,74 @@
 package rpc
 
 import (
-	"context"
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/common"
-	"log"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 )
 
-// Erc20Name collects name of the given ERC20 contract if possible.
-// Solidity: function name() view returns(string)
-func (a *Adapter) Erc20Name(adr common.Address) (string, error) {
-	// call ERC20
-	data, err := a.ftm.CallContract(context.Background(), ethereum.CallMsg{
-		From: common.Address{},
-		To:   &adr,
-		Data: common.Hex2Bytes("06fdde03"),
-	}, nil)
+// ErrEmptyResponse represents an error of a contract call returning no data, e.g. if the contract does not exist.
+var ErrEmptyResponse = errors.New("empty contract response")
 
-	if err != nil {
-		log.Println("can not get ERC20 name", err.Error())
-		return "", err
-	}
-
-	return decodeAbiString(data), nil
-}
+// ErrCallFailed represents an error of a contract call being reverted.
+var ErrCallFailed = errors.New("contract call failed")
 
-// Erc20Symbol collects symbol of the given ERC20 contract if possible.
-// Solidity: function symbol() view returns(string)
-func (a *Adapter) Erc20Symbol(adr common.Address) (string, error) {
-	// call ERC20
-	data, err := a.ftm.CallContract(context.Background(), ethereum.CallMsg{
-		From: common.Address{},
-		To:   &adr,
-		Data: common.Hex2Bytes("95d89b41"),
-	}, nil)
+// ErrMalformedResponse represents an error of a contract call returning data not matching the expected ABI type.
+var ErrMalformedResponse = errors.New("malformed contract response")
 
-	if err != nil {
-		log.Println("can not get ERC20 symbol", err.Error())
-		return "", err
+// decodeAbiUint8 decodes uint8 value from ABI format.
+func decodeAbiUint8(data []byte) (uint8, error) {
+	if len(data) == 0 {
+		return 0, ErrEmptyResponse
 	}
 
-	return decodeAbiString(data), nil
-}
-
-// Erc20Decimals collects number of decimals of the given ERC20 contract.
-// Solidity: function decimals() view returns(uint8)
-func (a *Adapter) Erc20Decimals(adr common.Address) (uint8, error) {
-	// call ERC20
-	data, err := a.ftm.CallContract(context.Background(), ethereum.CallMsg{
-		From: common.Address{},
-		To:   &adr,
-		Data: common.Hex2Bytes("313ce567"),
-	}, nil)
-
-	if err != nil {
-		log.Println("can not get ERC20 decimals", err.Error())
-		return 0, err
+	// even uint8 is encoded in 32 bytes by ABI
+	if len(data) < 32 {
+		return 0, fmt.Errorf("%w; uint8 expected, %d bytes received", ErrMalformedResponse, len(data))
 	}
 
-	// even uint8 is encoded in 32 bytes by ABI
+	// the padding must be empty
+	if new(big.Int).SetBytes(data[:31]).Sign() != 0 {
+		return 0, fmt.Errorf("%w; value out of uint8 range", ErrMalformedResponse)
+	}
 	return data[31], nil
 }
 
 // decodeAbiString decodes string from ABI format.
-func decodeAbiString(data []byte) string {
+// Some tokens (e.g. MKR) return bytes32 instead of the string, this format is recognized and decoded as well.
+func decodeAbiString(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", ErrEmptyResponse
+	}
+
+	// bytes32 value padded with zeros
+	if len(data) == 32 {
+		return sanitizeString(data), nil
+	}
+
 	// does it even make sense?
 	if len(data) < 64 {
-		return ""
+		return "", fmt.Errorf("%w; string expected, %d bytes received", ErrMalformedResponse, len(data))
 	}
 
-	// where the string starts and ends?
-	offset := new(big.Int).SetBytes(data[:32]).Uint64() + 32
-	length := new(big.Int).SetBytes(data[offset-32 : offset]).Uint64()
+	// where the string starts and ends? make sure it fits into the data received
+	size := new(big.Int).SetUint64(uint64(len(data)))
+	offset := new(big.Int).SetBytes(data[:32])
+	if new(big.Int).Add(offset, big.NewInt(32)).Cmp(size) > 0 {
+		return "", fmt.Errorf("%w; string offset %s out of range", ErrMalformedResponse, offset.String())
+	}
+
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[start-32 : start])
+	if new(big.Int).Add(length, new(big.Int).SetUint64(start)).Cmp(size) > 0 {
+		return "", fmt.Errorf("%w; string length %s out of range", ErrMalformedResponse, length.String())
+	}
+
+	return sanitizeString(data[start : start+length.Uint64()]), nil
+}
 
-	return string(data[offset : offset+length])
+// sanitizeString converts the given bytes into a printable string
+// removing NUL padding and invalid UTF-8 sequences.
+func sanitizeString(b []byte) string {
+	return strings.TrimSpace(strings.ToValidUTF8(strings.ReplaceAll(string(b), "\x00", ""), ""))
 }
@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// word encodes the value into a single ABI word.
+func word(v *big.Int) []byte {
+	w := make([]byte, 32)
+	return v.FillBytes(w)
+}
+
+// abiString encodes the given bytes as an ABI string with the given offset and length words.
+func abiString(offset *big.Int, length *big.Int, b []byte) []byte {
+	data := append(word(offset), word(length)...)
+	data = append(data, b...)
+	return append(data, make([]byte, (32-len(b)%32)%32)...)
+}
+
+func TestDecodeAbiString(t *testing.T) {
+	huge := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		err  error
+	}{
+		{"empty", nil, "", ErrEmptyResponse},
+		{"short", make([]byte, 31), "", ErrMalformedResponse},
+		{"between words", make([]byte, 48), "", ErrMalformedResponse},
+		{"valid", abiString(big.NewInt(32), big.NewInt(4), []byte("USDC")), "USDC", nil},
+		{"empty string", abiString(big.NewInt(32), big.NewInt(0), nil), "", nil},
+		{"offset past buffer", abiString(big.NewInt(4096), big.NewInt(4), []byte("USDC")), "", ErrMalformedResponse},
+		{"huge offset", abiString(huge, big.NewInt(4), []byte("USDC")), "", ErrMalformedResponse},
+		{"length past buffer", abiString(big.NewInt(32), big.NewInt(33), []byte("USDC")), "", ErrMalformedResponse},
+		{"huge length", abiString(big.NewInt(32), huge, []byte("USDC")), "", ErrMalformedResponse},
+		{"length overflowing uint64", abiString(big.NewInt(32), new(big.Int).SetUint64(^uint64(0)-31), []byte("USDC")), "", ErrMalformedResponse},
+		{"bytes32", append([]byte("MKR"), make([]byte, 29)...), "MKR", nil},
+		{"bytes32 inner padding", append([]byte("M\x00K\x00R"), make([]byte, 27)...), "MKR", nil},
+		{"bytes32 all padding", make([]byte, 32), "", nil},
+		{"invalid UTF-8", abiString(big.NewInt(32), big.NewInt(6), []byte("US\xff\xfeDC")), "USDC", nil},
+		{"invalid UTF-8 bytes32", append([]byte("\xc3\x28WFTM"), make([]byte, 26)...), "(WFTM", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeAbiString(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, expected %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("decoded %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeAbiUint8(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want uint8
+		err  error
+	}{
+		{"empty", nil, 0, ErrEmptyResponse},
+		{"short", []byte{18}, 0, ErrMalformedResponse},
+		{"valid", word(big.NewInt(18)), 18, nil},
+		{"zero", word(big.NewInt(0)), 0, nil},
+		{"max", word(big.NewInt(255)), 255, nil},
+		{"above range", word(big.NewInt(256)), 0, ErrMalformedResponse},
+		{"high bits", append([]byte{1}, make([]byte, 31)...), 0, ErrMalformedResponse},
+		{"trailing data", append(word(big.NewInt(6)), bytes.Repeat([]byte{0xff}, 32)...), 6, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeAbiUint8(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, expected %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("decoded %d, expected %d", got, tt.want)
+			}
+		})
+	}
+}
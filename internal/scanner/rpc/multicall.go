@@ -0,0 +1,179 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	client "github.com/ethereum/go-ethereum/rpc"
+	"log"
+	"math/big"
+	"strings"
+)
+
+// multicallBatchSize represents the maximal number of calls aggregated into a single request.
+const multicallBatchSize = 350
+
+// multicallAbi represents the ABI of the Multicall3 aggregation function.
+var multicallAbi = mustAbi(`[{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`)
+
+// Call represents a single contract call of a batch.
+type Call struct {
+	To   common.Address
+	Data []byte
+}
+
+// CallResult represents the result of a single contract call of a batch.
+type CallResult struct {
+	Data []byte
+	Err  error
+}
+
+// multicall3Call represents a call structure of the Multicall3 contract.
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result represents a call result structure of the Multicall3 contract.
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// detectMulticall checks if the Multicall3 contract is deployed at the given address.
+func (a *Adapter) detectMulticall(adr common.Address) {
+	if adr == (common.Address{}) {
+		return
+	}
+
+	code, err := a.ftm.CodeAt(context.Background(), adr, nil)
+	if err != nil || len(code) == 0 {
+		log.Println("multicall contract not available at", adr.String())
+		return
+	}
+
+	log.Println("multicall contract found at", adr.String())
+	a.multicall = &adr
+}
+
+// CallMany executes the given contract calls at the given block, or at the latest block if the block is nil.
+// The calls are aggregated through the Multicall3 contract, if available, or sent to the node
+// in batches of direct calls otherwise.
+func (a *Adapter) CallMany(calls []Call, block *big.Int) []CallResult {
+	res := make([]CallResult, 0, len(calls))
+	for len(calls) > 0 {
+		size := multicallBatchSize
+		if size > len(calls) {
+			size = len(calls)
+		}
+
+		res = append(res, a.callBatch(calls[:size], block)...)
+		calls = calls[size:]
+	}
+	return res
+}
+
+// callBatch executes the given batch of contract calls.
+func (a *Adapter) callBatch(calls []Call, block *big.Int) []CallResult {
+	if a.multicall != nil {
+		res, err := a.aggregate(calls, block)
+		if err == nil {
+			return res
+		}
+		log.Println("multicall failed, using direct calls", err.Error())
+	}
+	return a.batch(calls, block)
+}
+
+// aggregate executes the calls in a single request through the Multicall3 contract.
+func (a *Adapter) aggregate(calls []Call, block *big.Int) ([]CallResult, error) {
+	list := make([]multicall3Call, len(calls))
+	for i, c := range calls {
+		list[i] = multicall3Call{Target: c.To, AllowFailure: true, CallData: c.Data}
+	}
+
+	input, err := multicallAbi.Pack("aggregate3", list)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := a.ftm.CallContract(context.Background(), ethereum.CallMsg{
+		From: common.Address{},
+		To:   a.multicall,
+		Data: input,
+	}, block)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := multicallAbi.Unpack("aggregate3", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []multicall3Result
+	if err := multicallAbi.Methods["aggregate3"].Outputs.Copy(&ret, out); err != nil {
+		return nil, err
+	}
+
+	res := make([]CallResult, len(calls))
+	for i := range res {
+		if i >= len(ret) || !ret[i].Success {
+			res[i].Err = ErrCallFailed
+			continue
+		}
+		res[i].Data = ret[i].ReturnData
+	}
+	return res, nil
+}
+
+// batch executes the calls as a batch of direct calls.
+func (a *Adapter) batch(calls []Call, block *big.Int) []CallResult {
+	elems := make([]client.BatchElem, len(calls))
+	data := make([]hexutil.Bytes, len(calls))
+	for i, c := range calls {
+		elems[i] = client.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":   c.To,
+				"data": hexutil.Bytes(c.Data),
+			}, blockArg(block)},
+			Result: &data[i],
+		}
+	}
+
+	res := make([]CallResult, len(calls))
+	if err := a.rpc.BatchCallContext(context.Background(), elems); err != nil {
+		log.Println("batch call failed", err.Error())
+		for i := range res {
+			res[i].Err = err
+		}
+		return res
+	}
+
+	for i := range res {
+		res[i] = CallResult{Data: data[i], Err: elems[i].Error}
+	}
+	return res
+}
+
+// blockArg provides the block number argument of a call.
+func blockArg(block *big.Int) string {
+	if block == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(block)
+}
+
+// mustAbi parses the given ABI definition.
+func mustAbi(def string) abi.ABI {
+	a, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
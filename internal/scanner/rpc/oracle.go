@@ -0,0 +1,52 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"math/big"
+)
+
+// Round represents a price round of a Chainlink style aggregator contract.
+type Round struct {
+	RoundID   *big.Int `json:"roundId"`
+	Answer    *big.Int `json:"answer"`
+	UpdatedAt uint64   `json:"updatedAt"`
+	Decimals  uint8    `json:"decimals"`
+}
+
+// RoundAt provides the latest price round of the given aggregator contract at the given block,
+// or at the latest block if the block is zero. Archive node is needed to collect rounds of older blocks.
+func (a *Adapter) RoundAt(feed common.Address, blk uint64) (Round, error) {
+	var block *big.Int
+	if blk != 0 {
+		block = new(big.Int).SetUint64(blk)
+	}
+
+	res := a.CallMany([]Call{
+		{To: feed, Data: common.Hex2Bytes("feaf968c")}, // latestRoundData()
+		{To: feed, Data: common.Hex2Bytes("313ce567")}, // decimals()
+	}, block)
+
+	var r Round
+	for _, cr := range res {
+		if cr.Err != nil {
+			return r, cr.Err
+		}
+	}
+
+	// (uint80 roundId, int256 answer, uint256 startedAt, uint256 updatedAt, uint80 answeredInRound)
+	if len(res[0].Data) < 5*32 {
+		return r, ErrMalformedResponse
+	}
+
+	var err error
+	if r.Decimals, err = decodeAbiUint8(res[1].Data); err != nil {
+		return r, err
+	}
+
+	r.RoundID = new(big.Int).SetBytes(res[0].Data[:32])
+	r.Answer = math.S256(new(big.Int).SetBytes(res[0].Data[32:64]))
+	r.UpdatedAt = new(big.Int).SetBytes(res[0].Data[96:128]).Uint64()
+	return r, nil
+}
@@ -0,0 +1,22 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import "github.com/ethereum/go-ethereum/common"
+
+// pairTokenSigs represents the signatures of the functions providing the underlying tokens of a DEX pair.
+// Solidity: function token0() view returns(address), function token1() view returns(address)
+var pairTokenSigs = []string{"0dfe1681", "d21220a7"}
+
+// pairTokenCall builds the call collecting the address of the underlying token of the given DEX pair (pool) contract.
+func pairTokenCall(adr common.Address, idx int) Call {
+	return Call{To: adr, Data: common.Hex2Bytes(pairTokenSigs[idx])}
+}
+
+// decodeAbiAddress decodes an optional address from ABI format of the call result.
+func decodeAbiAddress(res CallResult) common.Address {
+	// address is encoded in 32 bytes by ABI
+	if res.Err != nil || len(res.Data) < 32 {
+		return common.Address{}
+	}
+	return common.BytesToAddress(res.Data[12:32])
+}
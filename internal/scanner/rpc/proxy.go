@@ -0,0 +1,88 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	client "github.com/ethereum/go-ethereum/rpc"
+	"log"
+)
+
+// proxyBatchSize represents the maximal number of contracts checked in a single batch.
+const proxyBatchSize = 100
+
+// ProxyEIP1967 represents the type of proxy contracts keeping the implementation in EIP-1967 storage slot.
+const ProxyEIP1967 = "EIP-1967"
+
+// ProxyEIP1822 represents the type of proxy contracts keeping the implementation in EIP-1822 (UUPS) storage slot.
+const ProxyEIP1822 = "EIP-1822"
+
+// eip1967ImplementationSlot represents the storage slot of EIP-1967 proxy implementation address.
+// Solidity: bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+var eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+// eip1822ProxiableSlot represents the storage slot of EIP-1822 proxy implementation address.
+// Solidity: keccak256("PROXIABLE")
+var eip1822ProxiableSlot = common.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7")
+
+// Proxy represents the detected proxy type and implementation of a contract.
+// The type is empty if the contract is not a known proxy.
+type Proxy struct {
+	Type           string
+	Implementation common.Address
+}
+
+// Proxies detects proxy implementations of the given contracts by reading their storage slots in batches.
+func (a *Adapter) Proxies(list []common.Address) []Proxy {
+	res := make([]Proxy, 0, len(list))
+	for len(list) > 0 {
+		size := proxyBatchSize
+		if size > len(list) {
+			size = len(list)
+		}
+
+		res = append(res, a.proxiesBatch(list[:size])...)
+		list = list[size:]
+	}
+	return res
+}
+
+// proxiesBatch detects proxy implementations of the given batch of contracts.
+func (a *Adapter) proxiesBatch(list []common.Address) []Proxy {
+	slots := []common.Hash{eip1967ImplementationSlot, eip1822ProxiableSlot}
+
+	elems := make([]client.BatchElem, 0, len(list)*len(slots))
+	data := make([]hexutil.Bytes, len(list)*len(slots))
+	for i, adr := range list {
+		for j, slot := range slots {
+			elems = append(elems, client.BatchElem{
+				Method: "eth_getStorageAt",
+				Args:   []interface{}{adr, slot, "latest"},
+				Result: &data[i*len(slots)+j],
+			})
+		}
+	}
+
+	res := make([]Proxy, len(list))
+	if err := a.rpc.BatchCallContext(context.Background(), elems); err != nil {
+		log.Println("can not read proxy slots", err.Error())
+		return res
+	}
+
+	for i := range list {
+		for j, typ := range []string{ProxyEIP1967, ProxyEIP1822} {
+			k := i*len(slots) + j
+			if elems[k].Error != nil || len(data[k]) != 32 {
+				continue
+			}
+
+			impl := common.BytesToAddress(data[k])
+			if impl != (common.Address{}) {
+				res[i] = Proxy{Type: typ, Implementation: impl}
+				break
+			}
+		}
+	}
+	return res
+}
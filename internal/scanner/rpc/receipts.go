@@ -0,0 +1,206 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	client "github.com/ethereum/go-ethereum/rpc"
+	"log"
+	"math/big"
+)
+
+// trxBatchSize represents the maximal number of transactions loaded in a single batch.
+const trxBatchSize = 100
+
+// TrxDetail represents execution details of a transaction collected from the transaction and its receipt.
+type TrxDetail struct {
+	Hash              common.Hash     `json:"hash"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	TxIndex           uint            `json:"transactionIndex"`
+	From              common.Address  `json:"from"`
+	To                *common.Address `json:"to"`
+	Created           *common.Address `json:"created"`
+	Nonce             uint64          `json:"nonce"`
+	Type              uint64          `json:"type"`
+	Value             *big.Int        `json:"value"`
+	Status            uint64          `json:"status"`
+	GasUsed           uint64          `json:"gasUsed"`
+	EffectiveGasPrice *big.Int        `json:"effectiveGasPrice"`
+	Err               error           `json:"-"`
+}
+
+// Recipient provides the recipient of the transaction; the recipient of a contract deployment is the created contract.
+func (td *TrxDetail) Recipient() common.Address {
+	switch {
+	case td.To != nil:
+		return *td.To
+	case td.Created != nil:
+		return *td.Created
+	}
+	return common.Address{}
+}
+
+// Fee provides the total fee paid for the transaction execution.
+func (td *TrxDetail) Fee() *big.Int {
+	if td.EffectiveGasPrice == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(td.EffectiveGasPrice, new(big.Int).SetUint64(td.GasUsed))
+}
+
+// rpcTransaction represents the transaction fields we need from the node.
+type rpcTransaction struct {
+	From     *common.Address `json:"from"`
+	To       *common.Address `json:"to"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Type     hexutil.Uint64  `json:"type"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+}
+
+// rpcReceipt represents the receipt fields we need from the node.
+type rpcReceipt struct {
+	BlockHash         common.Hash     `json:"blockHash"`
+	TransactionIndex  hexutil.Uint    `json:"transactionIndex"`
+	Status            hexutil.Uint64  `json:"status"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+}
+
+// TrxRecipients provides recipients of the given transactions, the recipient of a contract deployment is nil.
+// Transactions are loaded in batches without their receipts; failed lookups are not included.
+func (a *Adapter) TrxRecipients(list []common.Hash) map[common.Hash]*common.Address {
+	res := make(map[common.Hash]*common.Address, len(list))
+	for len(list) > 0 {
+		size := trxBatchSize
+		if size > len(list) {
+			size = len(list)
+		}
+
+		a.trxRecipientsBatch(list[:size], res)
+		list = list[size:]
+	}
+	return res
+}
+
+// trxRecipientsBatch loads recipients of the given batch of transactions into the result map.
+func (a *Adapter) trxRecipientsBatch(list []common.Hash, res map[common.Hash]*common.Address) {
+	txs := make([]*rpcTransaction, len(list))
+	elems := make([]client.BatchElem, len(list))
+	for i, h := range list {
+		elems[i] = client.BatchElem{Method: "eth_getTransactionByHash", Args: []interface{}{h}, Result: &txs[i]}
+	}
+
+	if err := a.rpc.BatchCallContext(context.Background(), elems); err != nil {
+		log.Println("transactions batch failed", err.Error())
+		return
+	}
+
+	for i, h := range list {
+		if elems[i].Error != nil || txs[i] == nil {
+			continue
+		}
+		res[h] = txs[i].To
+	}
+}
+
+// TrxDetails provides execution details of the given transactions.
+// Transactions and receipts are loaded in batches; failed lookups are marked by the error of the detail.
+func (a *Adapter) TrxDetails(list []common.Hash) []TrxDetail {
+	res := make([]TrxDetail, 0, len(list))
+	for len(list) > 0 {
+		size := trxBatchSize
+		if size > len(list) {
+			size = len(list)
+		}
+
+		res = append(res, a.trxDetailsBatch(list[:size])...)
+		list = list[size:]
+	}
+	return res
+}
+
+// trxDetailsBatch loads the given batch of transactions together with their receipts.
+func (a *Adapter) trxDetailsBatch(list []common.Hash) []TrxDetail {
+	txs := make([]json.RawMessage, len(list))
+	rcs := make([]*rpcReceipt, len(list))
+	elems := make([]client.BatchElem, 0, 2*len(list))
+	for i, h := range list {
+		elems = append(elems,
+			client.BatchElem{Method: "eth_getTransactionByHash", Args: []interface{}{h}, Result: &txs[i]},
+			client.BatchElem{Method: "eth_getTransactionReceipt", Args: []interface{}{h}, Result: &rcs[i]},
+		)
+	}
+
+	res := make([]TrxDetail, len(list))
+	err := a.rpc.BatchCallContext(context.Background(), elems)
+	if err != nil {
+		log.Println("transactions batch failed", err.Error())
+	}
+
+	for i, h := range list {
+		res[i].Hash = h
+		switch {
+		case err != nil:
+			res[i].Err = err
+		case elems[2*i].Error != nil:
+			res[i].Err = elems[2*i].Error
+		case elems[2*i+1].Error != nil:
+			res[i].Err = elems[2*i+1].Error
+		case rcs[i] == nil:
+			res[i].Err = fmt.Errorf("receipt of %s not found", h.String())
+		default:
+			res[i], res[i].Err = a.trxDetail(h, txs[i], rcs[i])
+		}
+	}
+	return res
+}
+
+// trxDetail builds the transaction detail from the raw transaction and its receipt.
+// Nodes not providing the effective gas price in receipts report the price paid as the transaction gas price.
+func (a *Adapter) trxDetail(h common.Hash, raw json.RawMessage, rc *rpcReceipt) (TrxDetail, error) {
+	tx, from, err := a.decodeTransaction(h, raw)
+	if err != nil {
+		return TrxDetail{Hash: h}, err
+	}
+
+	td := TrxDetail{
+		Hash:      h,
+		BlockHash: rc.BlockHash,
+		TxIndex:   uint(rc.TransactionIndex),
+		From:      from,
+		To:        tx.To,
+		Nonce:     uint64(tx.Nonce),
+		Type:      uint64(tx.Type),
+		Status:    uint64(rc.Status),
+		GasUsed:   uint64(rc.GasUsed),
+	}
+
+	if tx.Value != nil {
+		td.Value = tx.Value.ToInt()
+	}
+	if tx.To == nil {
+		td.Created = rc.ContractAddress
+	}
+
+	switch {
+	case rc.EffectiveGasPrice != nil:
+		td.EffectiveGasPrice = rc.EffectiveGasPrice.ToInt()
+	case tx.GasPrice != nil:
+		td.EffectiveGasPrice = tx.GasPrice.ToInt()
+	}
+	return td, nil
+}
+
+// TrxDetail provides execution details of a single transaction.
+func (a *Adapter) TrxDetail(tx common.Hash) (TrxDetail, error) {
+	td := a.TrxDetails([]common.Hash{tx})[0]
+	if td.Err != nil {
+		log.Println("failed to get transaction detail", td.Err.Error(), tx.String())
+	}
+	return td, td.Err
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	client "github.com/ethereum/go-ethereum/rpc"
@@ -16,8 +17,10 @@ import (
 
 // Adapter represents a communication interface to the Opera node.
 type Adapter struct {
-	rpc *client.Client
-	ftm *ethclient.Client
+	rpc       *client.Client
+	ftm       *ethclient.Client
+	multicall *common.Address
+	signer    types.Signer
 }
 
 // New creates a new RPC adapter.
@@ -27,10 +30,21 @@ func New(cfg *cfg.Config) (*Adapter, error) {
 		return nil, err
 	}
 
-	return &Adapter{
+	a := &Adapter{
 		rpc: con,
 		ftm: ethclient.NewClient(con),
-	}, nil
+	}
+
+	// the signer recognizes all the transaction types of the chain
+	id, err := a.ftm.ChainID(context.Background())
+	if err != nil {
+		log.Println("can not get chain ID", err.Error())
+		return nil, err
+	}
+	a.signer = types.LatestSignerForChainID(id)
+
+	a.detectMulticall(cfg.MulticallContract)
+	return a, nil
 }
 
 // connects opens RPC connection to the Opera node.
@@ -50,6 +64,15 @@ func (a *Adapter) TopBlock() (uint64, error) {
 	return a.ftm.BlockNumber(context.Background())
 }
 
+// ChainID provides the chain ID of the connected network.
+func (a *Adapter) ChainID() (uint64, error) {
+	id, err := a.ftm.ChainID(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return id.Uint64(), nil
+}
+
 // GetLogs provides a slice of log records for the given topics and blocks range.
 func (a *Adapter) GetLogs(topics [][]common.Hash, from uint64, to uint64) ([]types.Log, error) {
 	return a.ftm.FilterLogs(context.Background(), ethereum.FilterQuery{
@@ -60,6 +83,7 @@ func (a *Adapter) GetLogs(topics [][]common.Hash, from uint64, to uint64) ([]typ
 }
 
 // TrxRecipient provides a recipient of a transaction by hash.
+// The recipient of a contract deployment is the created contract.
 func (a *Adapter) TrxRecipient(tx common.Hash) (common.Address, error) {
 	trx, _, err := a.ftm.TransactionByHash(context.Background(), tx)
 	if err != nil {
@@ -69,28 +93,83 @@ func (a *Adapter) TrxRecipient(tx common.Hash) (common.Address, error) {
 
 	if trx.To() == nil {
 		log.Printf("contract deployment at %s", tx.String())
-		return common.Address{}, nil
+
+		rc, err := a.ftm.TransactionReceipt(context.Background(), tx)
+		if err != nil {
+			log.Println("failed to get receipt", err.Error(), tx.String())
+			return common.Address{}, err
+		}
+		return rc.ContractAddress, nil
 	}
 
 	return *trx.To(), nil
 }
 
-// TrxSender provides address of a sender of the given transaction.
-func (a *Adapter) TrxSender(tx common.Hash) (common.Address, error) {
-	trx, _, err := a.ftm.TransactionByHash(context.Background(), tx)
+// ValueTransactions provides hashes of transactions of the given block sending native value to the given address
+// by their index in the block.
+func (a *Adapter) ValueTransactions(blockNumber uint64, to common.Address) (map[uint]common.Hash, error) {
+	block, err := a.ftm.BlockByNumber(context.Background(), new(big.Int).SetUint64(blockNumber))
 	if err != nil {
-		log.Println("failed to get transaction", err.Error(), tx.String())
-		return common.Address{}, err
+		log.Println("failed to get block", blockNumber, err.Error())
+		return nil, err
 	}
 
-	// get transaction sender
-	msg, err := trx.AsMessage(types.NewEIP155Signer(trx.ChainId()), nil)
+	list := make(map[uint]common.Hash)
+	for i, tx := range block.Transactions() {
+		if tx.To() != nil && *tx.To() == to && tx.Value().Sign() > 0 {
+			list[uint(i)] = tx.Hash()
+		}
+	}
+	return list, nil
+}
+
+// codeBatchSize represents the maximal number of addresses checked for the code in a single batch.
+const codeBatchSize = 100
+
+// IsContract checks if the given address is a contract, i.e. has a code deployed.
+func (a *Adapter) IsContract(adr common.Address) (bool, error) {
+	code, err := a.ftm.CodeAt(context.Background(), adr, nil)
 	if err != nil {
-		log.Println("invalid transaction", err.Error())
-		return common.Address{}, err
+		log.Println("failed to get code", adr.String(), err.Error())
+		return false, err
 	}
+	return len(code) > 0, nil
+}
 
-	return msg.From(), nil
+// AreContracts checks which of the given addresses are contracts in batches.
+// Failed lookups are not included in the result.
+func (a *Adapter) AreContracts(list []common.Address) map[common.Address]bool {
+	res := make(map[common.Address]bool, len(list))
+	for len(list) > 0 {
+		size := codeBatchSize
+		if size > len(list) {
+			size = len(list)
+		}
+
+		a.areContractsBatch(list[:size], res)
+		list = list[size:]
+	}
+	return res
+}
+
+// areContractsBatch checks the code of the given batch of addresses into the result map.
+func (a *Adapter) areContractsBatch(list []common.Address, res map[common.Address]bool) {
+	code := make([]hexutil.Bytes, len(list))
+	elems := make([]client.BatchElem, len(list))
+	for i, adr := range list {
+		elems[i] = client.BatchElem{Method: "eth_getCode", Args: []interface{}{adr, "latest"}, Result: &code[i]}
+	}
+
+	if err := a.rpc.BatchCallContext(context.Background(), elems); err != nil {
+		log.Println("code batch failed", err.Error())
+		return
+	}
+
+	for i, adr := range list {
+		if elems[i].Error == nil {
+			res[adr] = len(code[i]) > 0
+		}
+	}
 }
 
 // BlockTime provides timestamp of a block by its number.
@@ -0,0 +1,50 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log"
+)
+
+// decodeTransaction decodes the RPC transaction object and provides its sender.
+func (a *Adapter) decodeTransaction(h common.Hash, raw json.RawMessage) (*rpcTransaction, common.Address, error) {
+	var tx *rpcTransaction
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return nil, common.Address{}, err
+	}
+	if tx == nil {
+		return nil, common.Address{}, fmt.Errorf("transaction %s not found", h.String())
+	}
+
+	from, err := a.sender(h, raw, tx.From)
+	return tx, from, err
+}
+
+// sender provides the sender of the raw transaction. The address reported by the node is preferred,
+// the sender recovered from the signature is used to verify it, or if the node did not report it.
+// The signer is chosen by the chain ID and the type of the transaction, pre EIP-155 transactions are supported.
+func (a *Adapter) sender(h common.Hash, raw json.RawMessage, from *common.Address) (common.Address, error) {
+	var rec common.Address
+	var tx types.Transaction
+
+	err := json.Unmarshal(raw, &tx)
+	if err == nil {
+		rec, err = types.Sender(a.signer, &tx)
+	}
+
+	switch {
+	case from == nil && err != nil:
+		log.Println("invalid transaction", h.String(), err.Error())
+		return common.Address{}, err
+	case from == nil:
+		return rec, nil
+	case err != nil:
+		log.Println("can not verify sender of", h.String(), err.Error())
+	case rec != *from:
+		log.Println("sender mismatch of", h.String(), "node reports", from.String(), "recovered", rec.String())
+	}
+	return *from, nil
+}
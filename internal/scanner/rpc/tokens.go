@@ -0,0 +1,183 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"log"
+	"math/big"
+)
+
+// erc1820Registry represents the address of the ERC-1820 pseudo-introspection registry.
+var erc1820Registry = common.HexToAddress("0x1820a4B7618BdE71Dce8cdc73aAB6C95905faD24")
+
+// erc777TokenHash represents the ERC-1820 interface hash of ERC-777 tokens.
+// Solidity: keccak256("ERC777Token")
+var erc777TokenHash = common.HexToHash("0xac7fbab5f54a3ca8194167523c6753bfeb96a445279294b6125b68cce2177054")
+
+// tokenInfoCallsCount represents the number of calls needed to collect metadata of a token.
+var tokenInfoCallsCount = len(tokenInfoCalls(common.Address{}))
+
+// tokenInfoCalls provides the list of contract calls used to collect metadata of the given token.
+func tokenInfoCalls(adr common.Address) []Call {
+	return []Call{
+		{To: adr, Data: common.Hex2Bytes("06fdde03")},  // name()
+		{To: adr, Data: common.Hex2Bytes("95d89b41")},  // symbol()
+		{To: adr, Data: common.Hex2Bytes("313ce567")},  // decimals()
+		{To: adr, Data: common.Hex2Bytes("18160ddd")},  // totalSupply()
+		pairTokenCall(adr, 0),                          // token0()
+		pairTokenCall(adr, 1),                          // token1()
+		vaultAssetCall(adr),                            // asset()
+		{To: adr, Data: supportsInterface("01ffc9a7")}, // ERC-165
+		{To: adr, Data: supportsInterface("ffffffff")}, // ERC-165 invalid interface
+		{To: adr, Data: supportsInterface("80ac58cd")}, // ERC-721
+		{To: adr, Data: supportsInterface("d9b67a26")}, // ERC-1155
+		{To: erc1820Registry, Data: append(append(common.Hex2Bytes("aabbb8ca"), adr.Hash().Bytes()...), erc777TokenHash.Bytes()...)},
+	}
+}
+
+// supportsInterface builds ERC-165 interface detection call data.
+// Solidity: function supportsInterface(bytes4 interfaceId) view returns (bool)
+func supportsInterface(id string) []byte {
+	return append(common.Hex2Bytes("01ffc9a7"), common.RightPadBytes(common.Hex2Bytes(id), 32)...)
+}
+
+// TokenInfo represents metadata of a token contract collected from the chain.
+// The underlying tokens of DEX pairs and ERC-4626 vaults are empty for other tokens.
+type TokenInfo struct {
+	Address     common.Address
+	Name        string
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+	Token0      common.Address
+	Token1      common.Address
+	Asset       common.Address
+
+	// detected interfaces and proxy implementation
+	Erc165         bool
+	Erc721         bool
+	Erc1155        bool
+	Erc777         bool
+	Proxy          string
+	Implementation common.Address
+
+	// Err represents the failure of the ERC20 metadata calls, if any.
+	Err error
+}
+
+// TokensInfo collects metadata of the given token contracts in as few requests as possible.
+func (a *Adapter) TokensInfo(list []common.Address) []TokenInfo {
+	calls := make([]Call, 0, len(list)*tokenInfoCallsCount)
+	for _, adr := range list {
+		calls = append(calls, tokenInfoCalls(adr)...)
+	}
+
+	res := a.CallMany(calls, nil)
+	proxies := a.Proxies(list)
+
+	info := make([]TokenInfo, len(list))
+	for i, adr := range list {
+		info[i] = decodeTokenInfo(adr, res[i*tokenInfoCallsCount:(i+1)*tokenInfoCallsCount])
+		info[i].Proxy, info[i].Implementation = proxies[i].Type, proxies[i].Implementation
+	}
+	return info
+}
+
+// decodeTokenInfo decodes token metadata from the results of the token info calls.
+func decodeTokenInfo(adr common.Address, res []CallResult) TokenInfo {
+	var err error
+	ti := TokenInfo{Address: adr}
+
+	ti.Name, err = decodeAbiString(res[0].Data)
+	ti.Err = firstError(ti.Err, res[0].Err, err, "name")
+
+	ti.Symbol, err = decodeAbiString(res[1].Data)
+	ti.Err = firstError(ti.Err, res[1].Err, err, "symbol")
+
+	ti.Decimals, err = decodeAbiUint8(res[2].Data)
+	ti.Err = firstError(ti.Err, res[2].Err, err, "decimals")
+
+	// total supply and underlying tokens are optional
+	if res[3].Err == nil && len(res[3].Data) >= 32 {
+		ti.TotalSupply = new(big.Int).SetBytes(res[3].Data[:32])
+	}
+
+	ti.Token0 = decodeAbiAddress(res[4])
+	ti.Token1 = decodeAbiAddress(res[5])
+	ti.Asset = decodeAbiAddress(res[6])
+
+	// ERC-165 requires the invalid interface to be rejected
+	ti.Erc165 = decodeAbiBool(res[7]) && !decodeAbiBool(res[8])
+	ti.Erc721 = ti.Erc165 && decodeAbiBool(res[9])
+	ti.Erc1155 = ti.Erc165 && decodeAbiBool(res[10])
+	ti.Erc777 = decodeAbiAddress(res[11]) == adr
+	return ti
+}
+
+// decodeAbiBool decodes an optional boolean from ABI format of the call result.
+func decodeAbiBool(res CallResult) bool {
+	return res.Err == nil && len(res.Data) == 32 && new(big.Int).SetBytes(res.Data).Cmp(big.NewInt(1)) == 0
+}
+
+// firstError provides the first error of a token metadata call, if any.
+func firstError(prev error, call error, dec error, fn string) error {
+	switch {
+	case prev != nil:
+		return prev
+	case call != nil:
+		return fmt.Errorf("%s call failed; %w", fn, call)
+	case dec != nil:
+		return fmt.Errorf("%s not decoded; %w", fn, dec)
+	}
+	return nil
+}
+
+// TokenState represents the block dependent state of a token.
+type TokenState struct {
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+}
+
+// TokenStateAt collects the state of the given token at the given block. Archive node is needed
+// to collect the state of older blocks.
+func (a *Adapter) TokenStateAt(adr common.Address, blk uint64) (TokenState, error) {
+	res := a.CallMany([]Call{
+		{To: adr, Data: common.Hex2Bytes("95d89b41")}, // symbol()
+		{To: adr, Data: common.Hex2Bytes("313ce567")}, // decimals()
+		{To: adr, Data: common.Hex2Bytes("18160ddd")}, // totalSupply()
+	}, new(big.Int).SetUint64(blk))
+
+	var err error
+	var ts TokenState
+	for _, r := range res {
+		if r.Err != nil {
+			return ts, r.Err
+		}
+	}
+
+	if ts.Symbol, err = decodeAbiString(res[0].Data); err != nil {
+		return ts, err
+	}
+	if ts.Decimals, err = decodeAbiUint8(res[1].Data); err != nil {
+		return ts, err
+	}
+	if len(res[2].Data) < 32 {
+		return ts, ErrMalformedResponse
+	}
+
+	ts.TotalSupply = new(big.Int).SetBytes(res[2].Data[:32])
+	return ts, nil
+}
+
+// HasHistory checks if the connected node provides state of the given historical block.
+func (a *Adapter) HasHistory(blk uint64) bool {
+	_, err := a.ftm.BalanceAt(context.Background(), common.Address{}, new(big.Int).SetUint64(blk))
+	if err != nil {
+		log.Println("historical state not available", blk, err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	client "github.com/ethereum/go-ethereum/rpc"
+	"log"
+	"math/big"
+)
+
+// methodNotFound represents the JSON-RPC error code of an unsupported method.
+const methodNotFound = -32601
+
+// callFrame represents a call of the callTracer output.
+type callFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Error string         `json:"error"`
+	Calls []callFrame    `json:"calls"`
+}
+
+// InternalTransfer represents native value transferred by an internal call of a transaction.
+type InternalTransfer struct {
+	CallType string
+	From     common.Address
+	To       common.Address
+	Value    *big.Int
+	Depth    int
+}
+
+// HasTracing checks if the connected node provides transaction call traces.
+func (a *Adapter) HasTracing() bool {
+	var res interface{}
+	err := a.rpc.CallContext(context.Background(), &res, "debug_traceTransaction", common.Hash{}, map[string]interface{}{"tracer": "callTracer"})
+
+	// the unknown transaction is expected to fail, the unknown method is not
+	var re client.Error
+	if errors.As(err, &re) && re.ErrorCode() == methodNotFound {
+		return false
+	}
+	return true
+}
+
+// InternalTransfers provides native value transfers of internal calls of the given transaction
+// collected by the callTracer. The top level call is not included, reverted calls are skipped.
+func (a *Adapter) InternalTransfers(tx common.Hash) ([]InternalTransfer, error) {
+	var root callFrame
+	err := a.rpc.CallContext(context.Background(), &root, "debug_traceTransaction", tx, map[string]interface{}{"tracer": "callTracer"})
+	if err != nil {
+		log.Println("failed to trace transaction", err.Error(), tx.String())
+		return nil, err
+	}
+
+	list := make([]InternalTransfer, 0)
+	for _, c := range root.Calls {
+		list = internalTransfers(c, 1, list)
+	}
+	return list, nil
+}
+
+// internalTransfers collects value transfers of the call and its sub-calls into the list.
+func internalTransfers(c callFrame, depth int, list []InternalTransfer) []InternalTransfer {
+	// nothing is transferred by a reverted call, including its sub-calls
+	if c.Error != "" {
+		return list
+	}
+
+	if c.Value != nil && c.Value.ToInt().Sign() > 0 {
+		list = append(list, InternalTransfer{
+			CallType: c.Type,
+			From:     c.From,
+			To:       c.To,
+			Value:    c.Value.ToInt(),
+			Depth:    depth,
+		})
+	}
+
+	for _, sub := range c.Calls {
+		list = internalTransfers(sub, depth+1, list)
+	}
+	return list
+}
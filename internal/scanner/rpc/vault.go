@@ -0,0 +1,10 @@
+// Package rpc implements Opera node communication wrappers through an adapter.
+package rpc
+
+import "github.com/ethereum/go-ethereum/common"
+
+// vaultAssetCall builds the call collecting address of the underlying asset token of the given ERC-4626 vault contract.
+// Solidity: function asset() view returns(address)
+func vaultAssetCall(adr common.Address) Call {
+	return Call{To: adr, Data: common.Hex2Bytes("38d52e0f")}
+}
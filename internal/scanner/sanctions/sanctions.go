@@ -0,0 +1,129 @@
+// Package sanctions implements screening of addresses against sanctions and deny lists loaded from files.
+package sanctions
+
+import (
+	"bufio"
+	"github.com/ethereum/go-ethereum/common"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxLineSize represents the maximal size of a line of a list file; OFAC style records can be long.
+const maxLineSize = 1024 * 1024
+
+// addressPattern represents the pattern of an address found anywhere on a line of a list file.
+var addressPattern = regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`)
+
+// list represents a single named list of addresses.
+type list struct {
+	name      string
+	path      string
+	modified  time.Time
+	addresses map[common.Address]bool
+}
+
+// Sanctions represents a set of named address lists.
+type Sanctions struct {
+	lists []*list
+}
+
+// New loads the given list files. Each file is specified as name=path, or just the path,
+// in which case the list is named by the file name without the extension.
+func New(files []string) (*Sanctions, error) {
+	s := &Sanctions{lists: make([]*list, 0, len(files))}
+	for _, spec := range files {
+		l := &list{path: spec}
+		if i := strings.Index(spec, "="); i > 0 {
+			l.name, l.path = spec[:i], spec[i+1:]
+		} else {
+			l.name = strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec))
+		}
+
+		if err := l.load(); err != nil {
+			log.Println("can not load address list", l.path, err.Error())
+			return nil, err
+		}
+		s.lists = append(s.lists, l)
+	}
+	return s, nil
+}
+
+// load reads addresses of the list from its file. Any address found on a line is listed, so both plain lists
+// and OFAC style CSV files with addresses inside of the record remarks are supported; lines starting with # are skipped.
+func (l *list) load() error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	adrs := make(map[common.Address]bool)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, adr := range addressPattern.FindAllString(line, -1) {
+			adrs[common.HexToAddress(adr)] = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	l.addresses = adrs
+	l.modified = fi.ModTime()
+	log.Println("address list", l.name, "loaded from", l.path, len(adrs), "addresses")
+	return nil
+}
+
+// Reload re-reads the list files changed since they have been loaded.
+// A list failing to load keeps its previous content.
+func (s *Sanctions) Reload() {
+	if s == nil {
+		return
+	}
+
+	for _, l := range s.lists {
+		fi, err := os.Stat(l.path)
+		if err != nil {
+			log.Println("can not check address list", l.path, err.Error())
+			continue
+		}
+		if fi.ModTime().Equal(l.modified) {
+			continue
+		}
+
+		if err := l.load(); err != nil {
+			log.Println("can not reload address list", l.path, err.Error())
+		}
+	}
+}
+
+// Lists provides names of the lists containing the address, if any.
+func (s *Sanctions) Lists(adr common.Address) []string {
+	if s == nil {
+		return nil
+	}
+
+	var names []string
+	for _, l := range s.lists {
+		if l.addresses[adr] {
+			names = append(names, l.name)
+		}
+	}
+	return names
+}
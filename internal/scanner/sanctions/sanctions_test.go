@@ -0,0 +1,132 @@
+package sanctions
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	tornado = common.HexToAddress("0x8589427373D6D84E98730D7795D8f6f8731FDA16")
+	lazarus = common.HexToAddress("0x098B716B8Aaf21512996dC57EB0615e2383E2f96")
+	scammer = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	nobody  = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+// writeList stores the list file in the given directory and provides its path.
+func writeList(t *testing.T, dir string, name string, lines ...string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLists(t *testing.T) {
+	dir := t.TempDir()
+	ofac := writeList(t, dir, "sdn.csv",
+		"# OFAC SDN list",
+		"",
+		`36189,"LAZARUS GROUP",-0-,"Digital Currency Address - ETH `+strings.ToLower(lazarus.Hex())+`"`,
+		`36190,"TORNADO CASH",-0-,"Digital Currency Address - ETH 0x`+strings.ToUpper(tornado.Hex()[2:])+`"`,
+		"# "+nobody.Hex(),
+	)
+	deny := writeList(t, dir, "deny.txt",
+		"   ",
+		"  "+scammer.Hex()+"  ",
+		strings.ToLower(tornado.Hex()),
+		"0x123",
+		"0x"+strings.Repeat("3", 41),
+		"0xZZZZ427373D6D84E98730D7795D8f6f8731FDA16",
+		"   # "+nobody.Hex(),
+	)
+
+	s, err := New([]string{"OFAC=" + ofac, deny})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		adr  common.Address
+		want []string
+	}{
+		{"lower case address", lazarus, []string{"OFAC"}},
+		{"upper case address on both lists", tornado, []string{"OFAC", "deny"}},
+		{"list named by file", scammer, []string{"deny"}},
+		{"commented out", nobody, nil},
+		{"too long address skipped", common.HexToAddress("0x" + strings.Repeat("3", 40)), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Lists(tt.adr); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("address listed on %v, expected %v", got, tt.want)
+			}
+		})
+	}
+
+	var empty *Sanctions
+	if got := empty.Lists(tornado); got != nil {
+		t.Errorf("address listed on %v without lists", got)
+	}
+}
+
+func TestNewMissingFile(t *testing.T) {
+	if _, err := New([]string{"OFAC=" + filepath.Join(t.TempDir(), "missing.csv")}); err == nil {
+		t.Error("missing list file accepted")
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	path := writeList(t, dir, "deny.txt", scammer.Hex())
+
+	s, err := New([]string{"deny=" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Lists(scammer); len(got) != 1 {
+		t.Fatalf("address listed on %v, expected deny", got)
+	}
+
+	// the content changes, but the modification time does not
+	loaded := s.lists[0].modified
+	writeList(t, dir, "deny.txt", nobody.Hex())
+	if err := os.Chtimes(path, loaded, loaded); err != nil {
+		t.Fatal(err)
+	}
+	s.Reload()
+	if got := s.Lists(nobody); got != nil {
+		t.Errorf("unchanged list reloaded, address listed on %v", got)
+	}
+
+	// the modification time changes
+	modified := loaded.Add(time.Minute)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	s.Reload()
+	if got := s.Lists(nobody); len(got) != 1 {
+		t.Errorf("address listed on %v after reload, expected deny", got)
+	}
+	if got := s.Lists(scammer); got != nil {
+		t.Errorf("removed address listed on %v after reload", got)
+	}
+
+	// a list failing to load keeps its content
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	s.Reload()
+	if got := s.Lists(nobody); len(got) != 1 {
+		t.Errorf("address listed on %v after failed reload, expected deny", got)
+	}
+
+	var empty *Sanctions
+	empty.Reload()
+}
@@ -4,7 +4,16 @@ package scanner
 import (
 	"erc20pump/internal/cfg"
 	"erc20pump/internal/scanner/cache"
+	"erc20pump/internal/scanner/labels"
+	"erc20pump/internal/scanner/pricetable"
+	"erc20pump/internal/scanner/registry"
+	"erc20pump/internal/scanner/reputation"
 	"erc20pump/internal/scanner/rpc"
+	"erc20pump/internal/scanner/sanctions"
+	"erc20pump/internal/scanner/tokenlist"
+	"erc20pump/internal/trx"
+	"fmt"
+	"log"
 	"sync"
 )
 
@@ -20,6 +29,15 @@ type Service struct {
 func New(c *cfg.Config) (*Service, error) {
 	wg := new(sync.WaitGroup)
 
+	if c.SchemaVersion != trx.SchemaV1 && c.SchemaVersion != trx.SchemaV2 {
+		log.Println("unknown output schema version", c.SchemaVersion)
+		return nil, fmt.Errorf("unknown output schema version %d", c.SchemaVersion)
+	}
+	if c.AwsAdminStream != "" && c.SchemaVersion != trx.SchemaV2 {
+		log.Println("admin stream requires output schema v2")
+		return nil, fmt.Errorf("admin stream requires output schema v2")
+	}
+
 	// create blockchain node adapter
 	ada, err := rpc.New(c)
 	if err != nil {
@@ -29,10 +47,58 @@ func New(c *cfg.Config) (*Service, error) {
 	// create cache
 	cch := cache.New()
 
+	// build event decoders
+	dec, err := newDecoders(c, ada)
+	if err != nil {
+		return nil, err
+	}
+
+	// load known tokens
+	reg, err := tokenRegistry(c)
+	if err != nil {
+		return nil, err
+	}
+
+	tl, err := tokenList(c, ada)
+	if err != nil {
+		return nil, err
+	}
+
+	rep, err := reputation.New(c.TokenDenyList, tl.Symbols(), reg.List())
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := newTokenResolver(reg, tl, rep, hasHistory(c, ada), ada, cch)
+
+	// load address labels
+	lbl, err := labels.New(c.LabelFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// load sanctions and deny lists of addresses
+	sl, err := sanctions.New(c.SanctionLists)
+	if err != nil {
+		return nil, err
+	}
+	ae := newAddressEnricher(lbl, sl, c.ClassifyAddresses, ada, cch)
+
+	// load price feeds
+	feeds, err := loadPriceFeeds(c.PriceFeeds)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := pricetable.New(c.PriceFiles)
+	if err != nil {
+		return nil, err
+	}
+	pe := newPriceEnricher(feeds, pt, c.PriceMaxAge, len(feeds) > 0 && isArchive(c, ada), ada, cch)
+
 	// make sub-services
-	lp := newPuller(c, ada, cch)
-	lc := newCollector(c, lp.output, ada, cch)
-	se := newSender(c, lc.output)
+	lp := newPuller(c, dec, tokens, ada, cch)
+	lc := newCollector(c, lp.output, dec, tokens, ae, pe, hasTracing(c, ada), ada, cch)
+	se := newSender(c, lc.output, lc.updates, reg)
 
 	// build the manager
 	return &Service{
@@ -43,6 +109,89 @@ func New(c *cfg.Config) (*Service, error) {
 	}, nil
 }
 
+// tokenRegistry opens the persistent token registry and imports additional tokens, if requested.
+func tokenRegistry(c *cfg.Config) (*registry.Registry, error) {
+	reg, err := registry.New(c.TokenStore)
+	if err != nil {
+		log.Println("can not open token registry", err.Error())
+		return nil, err
+	}
+
+	if c.TokenImport != "" {
+		if err := reg.Import(c.TokenImport); err != nil {
+			log.Println("can not import tokens", err.Error())
+			return nil, err
+		}
+		reg.Flush()
+	}
+	return reg, nil
+}
+
+// tokenList loads the configured token lists, if any.
+func tokenList(c *cfg.Config, rpc *rpc.Adapter) (*tokenlist.TokenList, error) {
+	if len(c.TokenLists) == 0 {
+		return nil, nil
+	}
+
+	id, err := rpc.ChainID()
+	if err != nil {
+		log.Println("can not get chain ID", err.Error())
+		return nil, err
+	}
+	return tokenlist.New(c.TokenLists, id, !c.TokenListAugment)
+}
+
+// hasHistory checks if the historical token state is requested and available on the connected node.
+func hasHistory(c *cfg.Config, rpc *rpc.Adapter) bool {
+	if !c.HistoricalTokens {
+		return false
+	}
+
+	if !isArchive(c, rpc) {
+		log.Println("archive node needed for historical token state, using the latest state")
+		return false
+	}
+	return true
+}
+
+// isArchive checks if the connected node provides the historical state of the scanned blocks.
+func isArchive(c *cfg.Config, rpc *rpc.Adapter) bool {
+	blk := c.StartBlock
+	if blk == 0 {
+		blk = 1
+	}
+	return rpc.HasHistory(blk)
+}
+
+// hasTracing checks if the internal native transfers are requested and the connected node provides call traces.
+func hasTracing(c *cfg.Config, rpc *rpc.Adapter) bool {
+	if !c.InternalTransfers {
+		return false
+	}
+
+	if !rpc.HasTracing() {
+		log.Println("node tracing not available, internal transfers are not collected")
+		return false
+	}
+	return true
+}
+
+// fatalf stores the token registry and terminates the app,
+// so the token metadata collected so far survive the crash.
+func fatalf(reg *registry.Registry, format string, v ...interface{}) {
+	reg.Flush()
+	log.Fatalf(format, v...)
+}
+
+// ExportTokens writes the content of the persistent token registry into the configured export file.
+func ExportTokens(c *cfg.Config) error {
+	reg, err := registry.New(c.TokenStore)
+	if err != nil {
+		return err
+	}
+	return reg.Export(c.TokenExport)
+}
+
 // Run the scanner service.
 func (s *Service) Run() {
 	// start all needed threads
@@ -54,6 +203,11 @@ func (s *Service) Run() {
 	s.wg.Wait()
 }
 
+// RefreshTokens signals the scanner to refresh metadata of all the known tokens.
+func (s *Service) RefreshTokens() {
+	s.lc.refresh()
+}
+
 // Stop the scanner service by signaling sub-services to terminate.
 func (s *Service) Stop() {
 	s.lp.stop()
@@ -6,11 +6,13 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"erc20pump/internal/cfg"
+	"erc20pump/internal/scanner/registry"
 	"erc20pump/internal/trx"
 	"fmt"
 	"github.com/aws/aws-sdk-go/service/kinesis"
 	"io/ioutil"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,26 +22,37 @@ import (
 
 // sender represents a sub-service responsible for sending collected transactions
 type sender struct {
-	input    chan trx.BlockchainTransaction
-	uploader *kinesis.Kinesis
-	lastSent   time.Time
-	streamName string
-	sigStop    chan bool
-	wg       *sync.WaitGroup
+	input        chan trx.BlockchainTransaction
+	updates      chan trx.TokenUpdate
+	uploader     *kinesis.Kinesis
+	registry     *registry.Registry
+	lastSent     time.Time
+	streamName   string
+	adminName    string
+	sanctionName string
+	schema       int
+	sigStop      chan bool
+	wg           *sync.WaitGroup
 }
 
 // newSender creates a new transaction sender instance.
-func newSender(config *cfg.Config, in chan trx.BlockchainTransaction) *sender {
+// The token registry is stored before the app terminates on a failed upload.
+func newSender(config *cfg.Config, in chan trx.BlockchainTransaction, upd chan trx.TokenUpdate, reg *registry.Registry) *sender {
 	sess := session.Must(session.NewSession(&aws.Config{
 		Region: aws.String(config.AwsRegion),
 	}))
 
 	return &sender{
-		input:      in,
-		uploader:   kinesis.New(sess),
-		lastSent:   time.Now(),
-		streamName: config.AwsStream,
-		sigStop:    make(chan bool, 1),
+		input:        in,
+		updates:      upd,
+		uploader:     kinesis.New(sess),
+		registry:     reg,
+		lastSent:     time.Now(),
+		streamName:   config.AwsStream,
+		adminName:    config.AwsAdminStream,
+		sanctionName: config.AwsSanctionStream,
+		schema:       config.SchemaVersion,
+		sigStop:      make(chan bool, 1),
 	}
 }
 
@@ -63,18 +76,36 @@ func (se *sender) observe() {
 		se.wg.Done()
 	}()
 
+	// closed channels are dropped from the select, the sender waits for the stop signal
+	input, updates := se.input, se.updates
 	for {
 		select {
 		case <-se.sigStop:
 			return
-		case tx := <-se.input:
+		case tx, ok := <-input:
+			if !ok {
+				input = nil
+				continue
+			}
 			se.process(tx)
+		case u, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			se.processUpdate(u)
 		}
 	}
 }
 
 // process adds the transaction into queue, sends if the queue is log/old enough
 func (se *sender) process(tx trx.BlockchainTransaction) {
+	// admin records are not part of the schema v1
+	if tx.Category == trx.CategoryAdmin && se.schema != trx.SchemaV2 {
+		log.Println("admin record skipped in schema v1", tx.TXHash.String())
+		return
+	}
+
 	// store locally instead if no bucket is specified
 	if se.streamName == "" {
 		se.save(tx)
@@ -83,19 +114,59 @@ func (se *sender) process(tx trx.BlockchainTransaction) {
 	}
 }
 
+// processUpdate stores or sends the token metadata change record. Updates are sent in the schema v2 only.
+func (se *sender) processUpdate(u trx.TokenUpdate) {
+	if se.schema != trx.SchemaV2 {
+		log.Println("token update skipped in schema v1", u.Token.Address.String())
+		return
+	}
+
+	data, err := json.MarshalIndent(trx.NewTokenUpdateV2(u), "", "    ")
+	if err != nil {
+		log.Println("can not encode token update into JSON", err.Error())
+		return
+	}
+
+	if se.streamName == "" {
+		name := fmt.Sprintf("token.%s.%s.json", u.Token.Address.String(), u.Timestamp)
+		log.Println("storing", name)
+
+		if err := ioutil.WriteFile(name, data, 0644); err != nil {
+			log.Println("can not write JSON to file", err.Error())
+		}
+		return
+	}
+
+	// updates of a token are kept in order within a single shard
+	key := u.Token.Address.String()
+	se.upload(se.streamName, key, data)
+	log.Printf("Uploaded token update into Kinesis")
+}
+
 // save stores the transaction data locally to a file.
 func (se *sender) save(tx trx.BlockchainTransaction) {
 	log.Println("storing", tx.TXHash.String())
 
 	// encode the transaction into a human-readable JSON struct
-	data, err := json.MarshalIndent(tx, "", "    ")
+	data, err := se.encode(tx)
 	if err != nil {
 		log.Println("can not encode to JSON", err.Error())
 		return
 	}
 
+	// admin records are stored aside of the transfers
+	name := tx.TXHash.String() + ".json"
+	if tx.Category == trx.CategoryAdmin {
+		name = tx.TXHash.String() + ".admin.json"
+	}
+
+	// records with sanctioned addresses are marked so they can be picked easily
+	if tx.Sanctioned() {
+		name = strings.TrimSuffix(name, ".json") + ".sanctioned.json"
+	}
+
 	// put the data into a file
-	err = ioutil.WriteFile(tx.TXHash.String()+".json", data, 0644)
+	err = ioutil.WriteFile(name, data, 0644)
 	if err != nil {
 		log.Println("can not write JSON to file", err.Error())
 	}
@@ -106,7 +177,7 @@ func (se *sender) send(tx trx.BlockchainTransaction) {
 	log.Printf("Sending transaction")
 
 	// encode the transaction into a human-readable JSON struct
-	data, err := json.MarshalIndent(tx, "", "    ")
+	data, err := se.encode(tx)
 	if err != nil {
 		fmt.Println("can not encode transaction into JSON", err.Error())
 		return
@@ -117,16 +188,41 @@ func (se *sender) send(tx trx.BlockchainTransaction) {
 	hash := md5.Sum(data)
 	dataHash := hex.EncodeToString(hash[:])
 
-	// put the data into the Kinesis data stream
-	_, err = se.uploader.PutRecord(&kinesis.PutRecordInput{
-		StreamName: &se.streamName,
-		Data:       data,
-		PartitionKey: &dataHash,
-	})
-	if err != nil {
-		log.Fatalf("Failed to upload into Kinesis; %s", err)
-		return
+	// admin records may have a dedicated stream to subscribe to
+	stream := se.streamName
+	if tx.Category == trx.CategoryAdmin && se.adminName != "" {
+		stream = se.adminName
 	}
 
+	se.upload(stream, dataHash, data)
 	log.Printf("Uploaded transaction into Kinesis")
+
+	// records with sanctioned addresses are copied to the compliance stream, if any
+	if se.sanctionName != "" && tx.Sanctioned() {
+		se.upload(se.sanctionName, dataHash, data)
+		log.Printf("Uploaded sanctioned transaction into Kinesis")
+	}
+}
+
+// encode provides the JSON encoding of the transaction in the configured schema version.
+// The schema v1 has no admin records, so the category is always omitted there.
+func (se *sender) encode(tx trx.BlockchainTransaction) ([]byte, error) {
+	if se.schema == trx.SchemaV2 {
+		return json.MarshalIndent(trx.NewBlockchainTransactionV2(tx), "", "    ")
+	}
+
+	tx.Category = ""
+	return json.MarshalIndent(tx, "", "    ")
+}
+
+// upload puts the data into the Kinesis data stream.
+func (se *sender) upload(stream string, key string, data []byte) {
+	_, err := se.uploader.PutRecord(&kinesis.PutRecordInput{
+		StreamName:   &stream,
+		Data:         data,
+		PartitionKey: &key,
+	})
+	if err != nil {
+		fatalf(se.registry, "Failed to upload into Kinesis; %s", err)
+	}
 }
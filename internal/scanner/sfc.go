@@ -0,0 +1,186 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/trx"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// sfcContracts represents a map of known chain IDs to the address of the SFC staking contract.
+var sfcContracts = map[uint64]common.Address{
+	250:  common.HexToAddress("0xFC00FACE00000000000000000000000000000000"), // Opera mainnet
+	4002: common.HexToAddress("0xFC00FACE00000000000000000000000000000000"), // Opera testnet
+}
+
+// sfcDecoders provides a map of SFC staking event topics to decoders
+// accepting only events emitted by the given SFC contract.
+func sfcDecoders(sfc common.Address) map[common.Hash]EventDecoder {
+	list := map[common.Hash]EventDecoder{
+		common.HexToHash("0x9a8f44850296624dadfd9c246d17e47171d35727a181bd090aa14bbbe00238bb"): decodeSfcDelegated,
+		common.HexToHash("0xd3bb4e423fbea695d16b982f9f682dc5f35152e5411646a8a5a79a6b02ba8d57"): decodeSfcUndelegated,
+		common.HexToHash("0x75e161b3e824b114fc1a33274bd7091918dd4e639cede50b78b15a4eea956a21"): decodeSfcWithdrawn,
+		common.HexToHash("0xc1d8eb6e444b89fb8ff0991c19311c070df704ccb009e210d1462d5b2410bf45"): decodeSfcClaimedRewards,
+		common.HexToHash("0x4119153d17a36f9597d40e3ab4148d03261a439dddbec4e91799ab7159608e26"): decodeSfcRestakedRewards,
+		common.HexToHash("0x138940e95abffcd789b497bf6188bba3afa5fbd22fb5c42c2f6018d1bf0f4e78"): decodeSfcLockedUpStake,
+		common.HexToHash("0xef6c0c14fe9aa51af36acd791464dec3badbde668b63189b47bfa4e25be9b2b9"): decodeSfcUnlockedStake,
+	}
+
+	// make sure we don't decode look-alike events of other contracts
+	for t, dec := range list {
+		list[t] = sfcOnly(sfc, dec)
+	}
+	return list
+}
+
+// sfcOnly wraps the SFC event decoder to reject events not emitted by the SFC contract.
+func sfcOnly(sfc common.Address, dec EventDecoder) EventDecoder {
+	return func(ev *types.Log, token func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+		if ev.Address != sfc {
+			return trx.Erc20Transaction{}, fmt.Errorf("staking event of unknown contract %s", ev.Address.String())
+		}
+		return dec(ev, token)
+	}
+}
+
+// decodeSfcDelegated decodes SFC stake delegation event.
+// Solidity: event Delegated(address indexed delegator, uint256 indexed toValidatorID, uint256 amount)
+func decodeSfcDelegated(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := sfcValues(ev, 3, 1)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	st := sfcStaking(ev)
+	return sfcEntry("DELEGATED", st.Delegator, ev.Address, val[0], st), nil
+}
+
+// decodeSfcUndelegated decodes SFC stake un-delegation event.
+// Solidity: event Undelegated(address indexed delegator, uint256 indexed toValidatorID, uint256 indexed wrID, uint256 amount)
+func decodeSfcUndelegated(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := sfcValues(ev, 4, 1)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	st := sfcStaking(ev)
+	st.WithdrawalID = ev.Topics[3].Big().String()
+	return sfcEntry("UNDELEGATED", st.Delegator, ev.Address, val[0], st), nil
+}
+
+// decodeSfcWithdrawn decodes SFC withdrawal of un-delegated stake event.
+// Solidity: event Withdrawn(address indexed delegator, uint256 indexed toValidatorID, uint256 indexed wrID, uint256 amount)
+func decodeSfcWithdrawn(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := sfcValues(ev, 4, 1)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	st := sfcStaking(ev)
+	st.WithdrawalID = ev.Topics[3].Big().String()
+	return sfcEntry("WITHDRAWN", ev.Address, st.Delegator, val[0], st), nil
+}
+
+// decodeSfcClaimedRewards decodes SFC rewards claim event.
+// Solidity: event ClaimedRewards(address indexed delegator, uint256 indexed toValidatorID, uint256 lockupExtraReward, uint256 lockupBaseReward, uint256 unlockedReward)
+func decodeSfcClaimedRewards(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := sfcValues(ev, 3, 3)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	st := sfcRewards(ev, val)
+	return sfcEntry("CLAIMED_REWARDS", ev.Address, st.Delegator, sfcSum(val), st), nil
+}
+
+// decodeSfcRestakedRewards decodes SFC rewards re-stake event.
+// Solidity: event RestakedRewards(address indexed delegator, uint256 indexed toValidatorID, uint256 lockupExtraReward, uint256 lockupBaseReward, uint256 unlockedReward)
+func decodeSfcRestakedRewards(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := sfcValues(ev, 3, 3)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	st := sfcRewards(ev, val)
+	return sfcEntry("RESTAKED_REWARDS", st.Delegator, ev.Address, sfcSum(val), st), nil
+}
+
+// decodeSfcLockedUpStake decodes SFC stake lock event.
+// Solidity: event LockedUpStake(address indexed delegator, uint256 indexed validatorID, uint256 duration, uint256 amount)
+func decodeSfcLockedUpStake(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := sfcValues(ev, 3, 2)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	st := sfcStaking(ev)
+	st.LockupDuration = val[0].String()
+	return sfcEntry("LOCKED_UP_STAKE", st.Delegator, ev.Address, val[1], st), nil
+}
+
+// decodeSfcUnlockedStake decodes SFC stake unlock event.
+// Solidity: event UnlockedStake(address indexed delegator, uint256 indexed validatorID, uint256 amount, uint256 penalty)
+func decodeSfcUnlockedStake(ev *types.Log, _ func(common.Address) trx.Token) (trx.Erc20Transaction, error) {
+	val, err := sfcValues(ev, 3, 2)
+	if err != nil {
+		return trx.Erc20Transaction{}, err
+	}
+
+	st := sfcStaking(ev)
+	st.Penalty = val[1].String()
+	return sfcEntry("UNLOCKED_STAKE", st.Delegator, ev.Address, val[0], st), nil
+}
+
+// sfcValues validates the SFC event layout and provides the non-indexed uint256 values.
+func sfcValues(ev *types.Log, topics int, words int) ([]*big.Int, error) {
+	if len(ev.Topics) != topics || len(ev.Data) < words*32 {
+		return nil, fmt.Errorf("invalid staking event at %s", ev.Address.String())
+	}
+
+	val := make([]*big.Int, words)
+	for i := range val {
+		val[i] = new(big.Int).SetBytes(ev.Data[i*32 : (i+1)*32])
+	}
+	return val, nil
+}
+
+// sfcStaking provides the base staking detail shared by all the SFC events.
+// The delegator and the validator ID are always the first two indexed values.
+func sfcStaking(ev *types.Log) *trx.Staking {
+	return &trx.Staking{
+		ValidatorID: ev.Topics[2].Big().String(),
+		Delegator:   common.BytesToAddress(ev.Topics[1].Bytes()),
+	}
+}
+
+// sfcRewards provides the staking detail of a rewards related SFC event.
+func sfcRewards(ev *types.Log, val []*big.Int) *trx.Staking {
+	st := sfcStaking(ev)
+	st.LockupExtraReward = val[0].String()
+	st.LockupBaseReward = val[1].String()
+	st.UnlockedReward = val[2].String()
+	return st
+}
+
+// sfcSum calculates the total of the given values.
+func sfcSum(val []*big.Int) *big.Int {
+	sum := new(big.Int)
+	for _, v := range val {
+		sum.Add(sum, v)
+	}
+	return sum
+}
+
+// sfcEntry builds the staking trx entry of native FTM flowing from the sender to the recipient.
+func sfcEntry(typ string, from common.Address, to common.Address, amount *big.Int, st *trx.Staking) trx.Erc20Transaction {
+	return trx.Erc20Transaction{
+		Token:     trx.NativeToken,
+		Type:      typ,
+		Sender:    from,
+		Recipient: to,
+		Amount:    amount.String(),
+		Staking:   st,
+	}
+}
@@ -0,0 +1,126 @@
+// Package tokenlist implements authoritative token metadata loaded from token lists
+// in the Uniswap token list JSON schema.
+package tokenlist
+
+import (
+	"encoding/json"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"io/ioutil"
+	"log"
+)
+
+// entry represents a token of a token list.
+type entry struct {
+	ChainID  uint64         `json:"chainId"`
+	Address  common.Address `json:"address"`
+	Name     string         `json:"name"`
+	Symbol   string         `json:"symbol"`
+	Decimals uint8          `json:"decimals"`
+	LogoURI  string         `json:"logoURI"`
+	Tags     []string       `json:"tags"`
+}
+
+// list represents the token list document.
+type list struct {
+	Name   string  `json:"name"`
+	Tokens []entry `json:"tokens"`
+}
+
+// TokenList represents a merged set of token lists.
+type TokenList struct {
+	tokens   map[common.Address]entry
+	override bool
+}
+
+// New loads the given token list files of the given chain. The files are ordered by priority,
+// the first list containing a token wins. If override is not set, the list metadata are used
+// only to augment the on-chain metadata, which are not available.
+func New(files []string, chainID uint64, override bool) (*TokenList, error) {
+	tl := &TokenList{
+		tokens:   make(map[common.Address]entry),
+		override: override,
+	}
+
+	for _, fn := range files {
+		data, err := ioutil.ReadFile(fn)
+		if err != nil {
+			log.Println("can not read token list", fn, err.Error())
+			return nil, err
+		}
+
+		var doc list
+		if err := json.Unmarshal(data, &doc); err != nil {
+			log.Println("can not decode token list", fn, err.Error())
+			return nil, err
+		}
+
+		var count int
+		for _, e := range doc.Tokens {
+			if e.ChainID != chainID {
+				continue
+			}
+			if _, ok := tl.tokens[e.Address]; ok {
+				continue
+			}
+
+			tl.tokens[e.Address] = e
+			count++
+		}
+
+		log.Println("token list loaded", doc.Name, "from", fn, count, "tokens")
+	}
+	return tl, nil
+}
+
+// Symbols provides symbols of all the listed tokens by the token address.
+func (tl *TokenList) Symbols() map[common.Address]string {
+	list := make(map[common.Address]string)
+	if tl == nil {
+		return list
+	}
+
+	for adr, e := range tl.tokens {
+		list[adr] = e.Symbol
+	}
+	return list
+}
+
+// Apply updates the token metadata with the token list detail, including the underlying tokens.
+func (tl *TokenList) Apply(tok trx.Token) trx.Token {
+	if tl == nil {
+		return tok
+	}
+
+	if tok.Pair != nil {
+		tok.Pair = &trx.TokenPair{
+			Token0: tl.Apply(tok.Pair.Token0),
+			Token1: tl.Apply(tok.Pair.Token1),
+		}
+	}
+
+	if tok.Asset != nil {
+		asset := tl.Apply(*tok.Asset)
+		tok.Asset = &asset
+	}
+
+	e, ok := tl.tokens[tok.Address]
+	if !ok {
+		return tok
+	}
+
+	if tl.override || tok.Name == "" || tok.Name == "unknown" {
+		tok.Name = e.Name
+	}
+	if tl.override || tok.Symbol == "" || tok.Symbol == "-" {
+		tok.Symbol = e.Symbol
+	}
+	if tl.override || tok.Decimals == 0 {
+		tok.Decimals = e.Decimals
+	}
+
+	tok.Verified = true
+	tok.LogoURI = e.LogoURI
+	tok.Tags = e.Tags
+	return tok
+}
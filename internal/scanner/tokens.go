@@ -0,0 +1,295 @@
+// Package scanner performs the scanning task.
+package scanner
+
+import (
+	"erc20pump/internal/scanner/cache"
+	"erc20pump/internal/scanner/registry"
+	"erc20pump/internal/scanner/reputation"
+	"erc20pump/internal/scanner/rpc"
+	"erc20pump/internal/scanner/tokenlist"
+	"erc20pump/internal/trx"
+	"github.com/ethereum/go-ethereum/common"
+	"log"
+	"time"
+)
+
+// tokenRetryDelay represents the delay before a failed token metadata lookup is retried.
+const tokenRetryDelay = 1 * time.Hour
+
+// tokenResolver represents a resolver of token metadata backed by the persistent token registry.
+type tokenResolver struct {
+	rpc        *rpc.Adapter
+	cache      *cache.MemCache
+	registry   *registry.Registry
+	tokenList  *tokenlist.TokenList
+	reputation *reputation.Reputation
+	history    bool
+}
+
+// newTokenResolver creates a new token metadata resolver.
+// If history is set, the block dependent token state is resolved at the block of the transfer.
+func newTokenResolver(reg *registry.Registry, tl *tokenlist.TokenList, rep *reputation.Reputation, history bool, rpc *rpc.Adapter, cache *cache.MemCache) *tokenResolver {
+	return &tokenResolver{
+		rpc:        rpc,
+		cache:      cache,
+		registry:   reg,
+		tokenList:  tl,
+		reputation: rep,
+		history:    history,
+	}
+}
+
+// token provides an ERC20 detail structure based on token contract address.
+// The block is the block where the token has been seen.
+func (tr *tokenResolver) token(adr common.Address, blk uint64) trx.Token {
+	tok := tr.chainToken(adr, blk)
+	if tr.history {
+		tok = tr.historical(tok, blk)
+	}
+	return tr.reputation.Apply(tr.tokenList.Apply(tok))
+}
+
+// zeroTransfer registers a zero value transfer of the token and re-evaluates its reputation.
+func (tr *tokenResolver) zeroTransfer(tok trx.Token, blk uint64) trx.Token {
+	tr.reputation.ZeroTransfer(tok.Address, blk)
+	return tr.reputation.Apply(tok)
+}
+
+// entry flags the token of the transaction entry based on the kind of the entry.
+func (tr *tokenResolver) entry(et trx.Erc20Transaction) trx.Erc20Transaction {
+	return tr.reputation.Entry(et)
+}
+
+// historical updates the token with its state at the given block, if available.
+// The latest state is kept if the historical state can not be collected.
+func (tr *tokenResolver) historical(tok trx.Token, blk uint64) trx.Token {
+	// only fungible tokens have the state we need
+	if tok.Standard != "ERC-20" && tok.Standard != "ERC-777" && tok.Standard != "ERC-4626" {
+		return tok
+	}
+
+	ts, err := tr.cache.TokenState(tok.Address, blk, tr.rpc.TokenStateAt)
+	if err != nil {
+		log.Println("token state not available", tok.Address.String(), blk, err.Error())
+		return tok
+	}
+
+	tok.Symbol = ts.Symbol
+	tok.Decimals = ts.Decimals
+	tok.TotalSupply = ts.TotalSupply.String()
+	tok.StateBlock = blk
+	return tok
+}
+
+// chainToken provides an ERC20 detail structure based on the token contract on-chain metadata.
+func (tr *tokenResolver) chainToken(adr common.Address, blk uint64) trx.Token {
+	rec, ok := tr.known(adr)
+	if !ok {
+		tr.resolve(map[common.Address]uint64{adr: blk})
+		rec, _ = tr.registry.Get(adr)
+	}
+	return rec.Token
+}
+
+// prefetch resolves metadata of the given tokens not known yet in a single batch.
+// The map contains the block where each of the tokens has been seen.
+func (tr *tokenResolver) prefetch(list map[common.Address]uint64) {
+	for adr := range list {
+		if _, ok := tr.known(adr); ok {
+			delete(list, adr)
+		}
+	}
+
+	if len(list) > 0 {
+		log.Println("resolving", len(list), "new tokens")
+		tr.resolve(list)
+	}
+}
+
+// known provides the registry record of the token, if the token is known.
+// Failed lookups are considered unknown after a while, so they are retried.
+func (tr *tokenResolver) known(adr common.Address) (registry.Record, bool) {
+	rec, ok := tr.registry.Get(adr)
+	if ok && (!rec.Failed || time.Since(rec.Refreshed) < tokenRetryDelay) {
+		return rec, true
+	}
+	return rec, false
+}
+
+// resolve collects metadata of the given tokens from the chain and stores them in the registry.
+// The underlying tokens of DEX pairs and ERC-4626 vaults are resolved as well.
+func (tr *tokenResolver) resolve(list map[common.Address]uint64) {
+	adrs := make([]common.Address, 0, len(list))
+	for adr := range list {
+		adrs = append(adrs, adr)
+	}
+
+	info := tr.rpc.TokensInfo(adrs)
+	for _, ti := range info {
+		rec := tokenRecord(ti, list[ti.Address])
+		if !rec.Failed {
+			log.Println("new token found", rec.Token.Name, "/", rec.Token.Symbol, "[", rec.Token.Decimals, "]")
+		}
+		tr.registry.Put(rec)
+	}
+
+	// collect underlying tokens we don't know yet
+	under := make(map[common.Address]uint64)
+	for _, ti := range info {
+		for _, u := range []common.Address{ti.Token0, ti.Token1, ti.Asset} {
+			if _, ok := tr.known(u); !ok && u != (common.Address{}) {
+				under[u] = list[ti.Address]
+			}
+		}
+	}
+	if len(under) > 0 {
+		tr.resolve(under)
+	}
+
+	// link the underlying tokens
+	for _, ti := range info {
+		rec, _ := tr.registry.Get(ti.Address)
+		if ti.Token0 != (common.Address{}) && ti.Token1 != (common.Address{}) && ti.Token0 != ti.Address && ti.Token1 != ti.Address {
+			log.Println("new pair found", ti.Address.String(), ti.Token0.String(), ti.Token1.String())
+			rec.Token.Pair = &trx.TokenPair{
+				Token0: tr.chainToken(ti.Token0, rec.FirstSeen),
+				Token1: tr.chainToken(ti.Token1, rec.FirstSeen),
+			}
+		}
+
+		if ti.Asset != (common.Address{}) && ti.Asset != ti.Address {
+			log.Println("new vault found", ti.Address.String(), ti.Asset.String())
+			asset := tr.chainToken(ti.Asset, rec.FirstSeen)
+			rec.Token.Asset = &asset
+		}
+
+		if rec.Token.Pair != nil || rec.Token.Asset != nil {
+			tr.registry.Put(rec)
+		}
+	}
+}
+
+// stale provides up to the given number of successfully resolved tokens not refreshed for the given time.
+func (tr *tokenResolver) stale(age time.Duration, limit int) []common.Address {
+	list := make([]common.Address, 0)
+	for _, rec := range tr.registry.List() {
+		if len(list) >= limit {
+			break
+		}
+		if !rec.Failed && time.Since(rec.Refreshed) >= age {
+			list = append(list, rec.Token.Address)
+		}
+	}
+	return list
+}
+
+// refresh re-reads on-chain metadata of the given known tokens and provides the list of changes found.
+// Tokens failing the lookup keep their previous metadata.
+func (tr *tokenResolver) refresh(adrs []common.Address) []trx.TokenUpdate {
+	updates := make([]trx.TokenUpdate, 0)
+	for _, ti := range tr.rpc.TokensInfo(adrs) {
+		old, ok := tr.registry.Get(ti.Address)
+		if !ok {
+			continue
+		}
+
+		rec := tokenRecord(ti, old.FirstSeen)
+		if rec.Failed && !old.Failed {
+			old.Refreshed = rec.Refreshed
+			tr.registry.Put(old)
+			continue
+		}
+
+		// the underlying tokens are not expected to change
+		rec.Token.Pair = old.Token.Pair
+		rec.Token.Asset = old.Token.Asset
+		tr.registry.Put(rec)
+
+		if changes := tokenChanges(old.Token, rec.Token); len(changes) > 0 {
+			log.Println("token updated", ti.Address.String(), changes)
+			updates = append(updates, trx.TokenUpdate{
+				Type:     trx.TokenUpdated,
+				Token:    rec.Token,
+				Previous: old.Token,
+				Changes:  changes,
+			})
+		}
+	}
+	return updates
+}
+
+// tokenChanges provides the list of token metadata fields changed between the two versions of the token.
+func tokenChanges(old, tok trx.Token) []string {
+	changes := make([]string, 0)
+	if old.Name != tok.Name {
+		changes = append(changes, "name")
+	}
+	if old.Symbol != tok.Symbol {
+		changes = append(changes, "symbol")
+	}
+	if old.Decimals != tok.Decimals {
+		changes = append(changes, "decimals")
+	}
+	if (old.Implementation == nil) != (tok.Implementation == nil) ||
+		(old.Implementation != nil && *old.Implementation != *tok.Implementation) {
+		changes = append(changes, "implementation")
+	}
+	return changes
+}
+
+// tokenStandard detects the token standard implemented by the token contract.
+// ERC-165 introspection is preferred, ERC-1820 registry and implemented functions are used otherwise.
+func tokenStandard(ti rpc.TokenInfo) string {
+	switch {
+	case ti.Erc1155:
+		return "ERC-1155"
+	case ti.Erc721:
+		return "ERC-721"
+	case ti.Erc777:
+		return "ERC-777"
+	case ti.Err == nil && ti.Asset != (common.Address{}):
+		return "ERC-4626"
+	case ti.Err == nil && ti.TotalSupply != nil:
+		return "ERC-20"
+	}
+	return ""
+}
+
+// tokenRecord builds the registry record of the token from the token metadata collected.
+func tokenRecord(ti rpc.TokenInfo, blk uint64) registry.Record {
+	rec := registry.Record{
+		Token: trx.Token{
+			Address:  ti.Address,
+			Name:     ti.Name,
+			Symbol:   ti.Symbol,
+			Decimals: ti.Decimals,
+		},
+		FirstSeen: blk,
+		Refreshed: time.Now(),
+	}
+
+	if ti.TotalSupply != nil {
+		rec.Token.TotalSupply = ti.TotalSupply.String()
+	}
+
+	rec.Token.Standard = tokenStandard(ti)
+	if ti.Proxy != "" {
+		impl := ti.Implementation
+		rec.Token.Proxy = ti.Proxy
+		rec.Token.Implementation = &impl
+	}
+
+	if ti.Err != nil {
+		log.Println("token lookup failed", ti.Err.Error(), ti.Address.Hex())
+		rec.Failed = true
+		rec.Error = ti.Err.Error()
+
+		if rec.Token.Name == "" {
+			rec.Token.Name = "unknown"
+		}
+		if rec.Token.Symbol == "" {
+			rec.Token.Symbol = "-"
+		}
+	}
+	return rec
+}
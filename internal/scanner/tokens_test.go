@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"erc20pump/internal/scanner/rpc"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"testing"
+)
+
+func TestTokenStandard(t *testing.T) {
+	supply := big.NewInt(1000)
+	asset := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	failed := errors.New("execution reverted")
+
+	tests := []struct {
+		name string
+		ti   rpc.TokenInfo
+		want string
+	}{
+		{"erc-1155 over everything", rpc.TokenInfo{Erc1155: true, Erc721: true, Erc777: true, Asset: asset, TotalSupply: supply}, "ERC-1155"},
+		{"erc-721 over erc-777", rpc.TokenInfo{Erc721: true, Erc777: true, Asset: asset, TotalSupply: supply}, "ERC-721"},
+		{"erc-777 over erc-4626", rpc.TokenInfo{Erc777: true, Asset: asset, TotalSupply: supply}, "ERC-777"},
+		{"erc-4626 over erc-20", rpc.TokenInfo{Asset: asset, TotalSupply: supply}, "ERC-4626"},
+		{"erc-20", rpc.TokenInfo{TotalSupply: supply}, "ERC-20"},
+		{"erc-721 without metadata", rpc.TokenInfo{Erc721: true, Err: failed}, "ERC-721"},
+		{"failed vault", rpc.TokenInfo{Asset: asset, TotalSupply: supply, Err: failed}, ""},
+		{"failed metadata", rpc.TokenInfo{TotalSupply: supply, Err: failed}, ""},
+		{"no supply", rpc.TokenInfo{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenStandard(tt.ti); got != tt.want {
+				t.Errorf("standard %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
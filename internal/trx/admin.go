@@ -0,0 +1,22 @@
+// Package trx implements transaction types.
+package trx
+
+import "github.com/ethereum/go-ethereum/common"
+
+// CategoryTransfer represents the category of records carrying token movements.
+const CategoryTransfer = "TRANSFER"
+
+// CategoryAdmin represents the category of records carrying administrative changes of watched contracts.
+const CategoryAdmin = "ADMIN"
+
+// Admin represents details of an administrative or governance operation on a contract.
+type Admin struct {
+	Role        string           `json:"role,omitempty"`
+	ProposalID  string           `json:"proposalId,omitempty"`
+	Support     *uint8           `json:"support,omitempty"`
+	Weight      string           `json:"weight,omitempty"`
+	Targets     []common.Address `json:"targets,omitempty"`
+	StartBlock  string           `json:"startBlock,omitempty"`
+	EndBlock    string           `json:"endBlock,omitempty"`
+	Description string           `json:"description,omitempty"`
+}
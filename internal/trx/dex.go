@@ -0,0 +1,24 @@
+// Package trx implements transaction types.
+package trx
+
+import "github.com/ethereum/go-ethereum/common"
+
+// TokenPair represents the underlying tokens of a DEX pair (pool) contract.
+type TokenPair struct {
+	Token0 Token `json:"token0"`
+	Token1 Token `json:"token1"`
+}
+
+// Dex represents details of a DEX pair (pool) operation.
+type Dex struct {
+	Protocol  string         `json:"protocol"`
+	Pair      common.Address `json:"pair"`
+	TokenIn   *Token         `json:"tokenIn,omitempty"`
+	AmountIn  string         `json:"amountIn,omitempty"`
+	TokenOut  *Token         `json:"tokenOut,omitempty"`
+	AmountOut string         `json:"amountOut,omitempty"`
+	Amount0   string         `json:"amount0,omitempty"`
+	Amount1   string         `json:"amount1,omitempty"`
+	Reserve0  string         `json:"reserve0,omitempty"`
+	Reserve1  string         `json:"reserve1,omitempty"`
+}
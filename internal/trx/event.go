@@ -0,0 +1,17 @@
+// Package trx implements transaction types.
+package trx
+
+// Event represents a generic contract event decoded from the contract ABI.
+type Event struct {
+	Name      string          `json:"name"`
+	Signature string          `json:"signature"`
+	Arguments []EventArgument `json:"arguments"`
+}
+
+// EventArgument represents a single named and typed argument of a decoded event.
+type EventArgument struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed"`
+	Value   interface{} `json:"value"`
+}
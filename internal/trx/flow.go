@@ -0,0 +1,14 @@
+// Package trx implements transaction types.
+package trx
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Flow represents the net change of a token balance of an address across all the entries of a transaction.
+type Flow struct {
+	Address      common.Address `json:"address"`
+	User         bool           `json:"user,omitempty"`
+	Token        common.Address `json:"token"`
+	Symbol       string         `json:"symbol"`
+	Delta        string         `json:"delta"`
+	DeltaDecimal string         `json:"deltaDecimal,omitempty"`
+}
@@ -0,0 +1,21 @@
+// Package trx implements transaction types.
+package trx
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Sources of prices.
+const (
+	PriceSourceOracle = "ORACLE"
+	PriceSourceFile   = "FILE"
+)
+
+// Price represents the USD price of the token and the USD value of the amount transferred.
+type Price struct {
+	Source    string          `json:"source"`
+	Feed      *common.Address `json:"feed,omitempty"`
+	RoundID   string          `json:"roundId,omitempty"`
+	Price     string          `json:"price"`
+	UpdatedAt uint64          `json:"updatedAt"`
+	Age       int64           `json:"age"`
+	ValueUSD  string          `json:"valueUsd"`
+}
@@ -0,0 +1,16 @@
+// Package trx implements transaction types.
+package trx
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Staking represents details of a staking operation on the SFC contract.
+type Staking struct {
+	ValidatorID       string         `json:"validatorId"`
+	Delegator         common.Address `json:"delegator"`
+	WithdrawalID      string         `json:"withdrawalId,omitempty"`
+	LockupDuration    string         `json:"lockupDuration,omitempty"`
+	Penalty           string         `json:"penalty,omitempty"`
+	LockupExtraReward string         `json:"lockupExtraReward,omitempty"`
+	LockupBaseReward  string         `json:"lockupBaseReward,omitempty"`
+	UnlockedReward    string         `json:"unlockedReward,omitempty"`
+}
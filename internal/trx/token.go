@@ -5,8 +5,41 @@ import "github.com/ethereum/go-ethereum/common"
 
 // Token represents a description of an ERC20 token.
 type Token struct {
-	Address  common.Address `json:"address"`
-	Name     string         `json:"name"`
-	Symbol   string         `json:"symbol"`
-	Decimals uint8          `json:"decimals"`
+	Address        common.Address  `json:"address"`
+	Name           string          `json:"name"`
+	Symbol         string          `json:"symbol"`
+	Decimals       uint8           `json:"decimals"`
+	TotalSupply    string          `json:"totalSupply,omitempty"`
+	StateBlock     uint64          `json:"stateBlock,omitempty"`
+	Standard       string          `json:"standard,omitempty"`
+	Proxy          string          `json:"proxy,omitempty"`
+	Implementation *common.Address `json:"implementation,omitempty"`
+	Pair           *TokenPair      `json:"pair,omitempty"`
+	Asset          *Token          `json:"asset,omitempty"`
+	Verified       bool            `json:"verified"`
+	LogoURI        string          `json:"logoURI,omitempty"`
+	Tags           []string        `json:"tags,omitempty"`
+	Flagged        bool            `json:"flagged"`
+	Flags          []string        `json:"flags,omitempty"`
+}
+
+// StandardNative represents the standard of the native coin of the chain.
+const StandardNative = "NATIVE"
+
+// NativeToken represents the native FTM coin of the Opera chain.
+var NativeToken = Token{
+	Name:     "Fantom",
+	Symbol:   "FTM",
+	Decimals: 18,
+	Standard: StandardNative,
+}
+
+// HasDecimals checks if the decimals of the token are known. Fungible tokens have the decimals
+// resolved from the chain, listed tokens of other standards have them from the token list.
+func (t Token) HasDecimals() bool {
+	switch t.Standard {
+	case "ERC-20", "ERC-777", "ERC-4626", StandardNative:
+		return true
+	}
+	return t.Verified
 }
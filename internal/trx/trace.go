@@ -0,0 +1,8 @@
+// Package trx implements transaction types.
+package trx
+
+// Trace represents details of an internal call of a transaction.
+type Trace struct {
+	CallType string `json:"callType"`
+	Depth    int    `json:"depth"`
+}
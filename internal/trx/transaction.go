@@ -5,21 +5,80 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// Kinds of addresses.
+const (
+	KindEOA      = "EOA"
+	KindContract = "CONTRACT"
+)
+
+// Execution status of a transaction.
+const (
+	StatusSuccess = "SUCCESS"
+	StatusFailed  = "FAILED"
+)
+
 // BlockchainTransaction represents a blockchain transaction.
 type BlockchainTransaction struct {
-	TXHash       common.Hash        `json:"hash"`
-	BlockNumber  string             `json:"blockNumber"`
-	Timestamp    string             `json:"timestamp"`
-	From         common.Address     `json:"from"`
-	To           common.Address     `json:"to"`
-	Transactions []Erc20Transaction `json:"erc20Transactions"`
+	TXHash        common.Hash        `json:"hash"`
+	Category      string             `json:"category,omitempty"`
+	BlockNumber   string             `json:"blockNumber"`
+	Timestamp     string             `json:"timestamp"`
+	BlockHash     common.Hash        `json:"-"`
+	TxIndex       uint               `json:"-"`
+	From          common.Address     `json:"from"`
+	FromLabel     string             `json:"fromLabel,omitempty"`
+	FromKind      string             `json:"fromKind,omitempty"`
+	FromSanctions []string           `json:"fromSanctions,omitempty"`
+	To            common.Address     `json:"to"`
+	ToLabel       string             `json:"toLabel,omitempty"`
+	ToKind        string             `json:"toKind,omitempty"`
+	ToSanctions   []string           `json:"toSanctions,omitempty"`
+	Status        string             `json:"status,omitempty"`
+	Type          string             `json:"type,omitempty"`
+	Nonce         string             `json:"nonce,omitempty"`
+	GasUsed       string             `json:"gasUsed,omitempty"`
+	GasPrice      string             `json:"effectiveGasPrice,omitempty"`
+	Fee           string             `json:"fee,omitempty"`
+	FeeDecimal    string             `json:"feeDecimal,omitempty"`
+	Transactions  []Erc20Transaction `json:"erc20Transactions"`
+	Flows         []Flow             `json:"netFlows,omitempty"`
 }
 
 // Erc20Transaction represents an ERC20 token transaction as part of the blockchain transaction.
 type Erc20Transaction struct {
-	Token     Token          `json:"token"`
-	Type      string         `json:"trxType"`
-	Sender    common.Address `json:"sender"`
-	Recipient common.Address `json:"recipient"`
-	Amount    string         `json:"amount"`
+	Token              Token          `json:"token"`
+	Type               string         `json:"trxType"`
+	Sender             common.Address `json:"sender"`
+	SenderLabel        string         `json:"senderLabel,omitempty"`
+	SenderKind         string         `json:"senderKind,omitempty"`
+	SenderSanctions    []string       `json:"senderSanctions,omitempty"`
+	Recipient          common.Address `json:"recipient"`
+	RecipientLabel     string         `json:"recipientLabel,omitempty"`
+	RecipientKind      string         `json:"recipientKind,omitempty"`
+	RecipientSanctions []string       `json:"recipientSanctions,omitempty"`
+	Amount             string         `json:"amount"`
+	AmountDecimal      string         `json:"amountDecimal,omitempty"`
+	AmountHex          string         `json:"amountHex,omitempty"`
+	Event              *Event         `json:"event,omitempty"`
+	Staking            *Staking       `json:"staking,omitempty"`
+	Dex                *Dex           `json:"dex,omitempty"`
+	Vault              *Vault         `json:"vault,omitempty"`
+	Erc777             *Erc777        `json:"erc777,omitempty"`
+	Admin              *Admin         `json:"admin,omitempty"`
+	Price              *Price         `json:"price,omitempty"`
+	Trace              *Trace         `json:"trace,omitempty"`
+	LogIndex           *uint          `json:"-"`
+}
+
+// Sanctioned checks if any address of the transaction, including its entries, is on a sanctions or deny list.
+func (tx BlockchainTransaction) Sanctioned() bool {
+	if len(tx.FromSanctions) > 0 || len(tx.ToSanctions) > 0 {
+		return true
+	}
+	for _, et := range tx.Transactions {
+		if len(et.SenderSanctions) > 0 || len(et.RecipientSanctions) > 0 {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,16 @@
+// Package trx implements transaction types.
+package trx
+
+// TokenUpdated represents the type of records carrying token metadata changes.
+const TokenUpdated = "TOKEN_UPDATED"
+
+// TokenUpdate represents a change of on-chain token metadata, a record of the slowly changing token dimension.
+// The metadata are read at the latest block, so the update is stamped with the time of the refresh.
+// Updates are not part of the schema v1, they are sent in the schema v2 only, see TokenUpdateV2.
+type TokenUpdate struct {
+	Type      string   `json:"recordType"`
+	Token     Token    `json:"token"`
+	Previous  Token    `json:"previous"`
+	Changes   []string `json:"changes"`
+	Timestamp string   `json:"timestamp"`
+}
@@ -0,0 +1,131 @@
+// Package trx implements transaction types.
+package trx
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"strconv"
+	"time"
+)
+
+// Schema versions of the output records.
+const (
+	SchemaV1 = 1
+	SchemaV2 = 2
+)
+
+// BlockchainTransactionV2 represents a blockchain transaction in the schema v2 with typed fields.
+type BlockchainTransactionV2 struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	TXHash        common.Hash    `json:"hash"`
+	Category      string         `json:"category"`
+	BlockNumber   uint64         `json:"blockNumber"`
+	BlockHash     common.Hash    `json:"blockHash"`
+	TxIndex       uint           `json:"transactionIndex"`
+	Timestamp     uint64         `json:"timestamp"`
+	Time          string         `json:"time"`
+	From          common.Address `json:"from"`
+	FromLabel     string         `json:"fromLabel,omitempty"`
+	FromKind      string         `json:"fromKind,omitempty"`
+	FromSanctions []string       `json:"fromSanctions,omitempty"`
+	To            common.Address `json:"to"`
+	ToLabel       string         `json:"toLabel,omitempty"`
+	ToKind        string         `json:"toKind,omitempty"`
+	ToSanctions   []string       `json:"toSanctions,omitempty"`
+	Status        string         `json:"status,omitempty"`
+	Type          *uint64        `json:"type,omitempty"`
+	Nonce         *uint64        `json:"nonce,omitempty"`
+	GasUsed       *uint64        `json:"gasUsed,omitempty"`
+	GasPrice      string         `json:"effectiveGasPrice,omitempty"`
+	Fee           string         `json:"fee,omitempty"`
+	FeeDecimal    string         `json:"feeDecimal,omitempty"`
+	Entries       []EntryV2      `json:"entries"`
+	Flows         []Flow         `json:"netFlows,omitempty"`
+}
+
+// EntryV2 represents a token operation of the transaction in the schema v2.
+// The log index is not available for entries not decoded from logs, e.g. native transfers.
+type EntryV2 struct {
+	Index    int   `json:"index"`
+	LogIndex *uint `json:"logIndex"`
+	Erc20Transaction
+}
+
+// NewBlockchainTransactionV2 converts the transaction into the schema v2.
+func NewBlockchainTransactionV2(tx BlockchainTransaction) BlockchainTransactionV2 {
+	v2 := BlockchainTransactionV2{
+		SchemaVersion: SchemaV2,
+		TXHash:        tx.TXHash,
+		Category:      tx.Category,
+		BlockNumber:   parseUint(tx.BlockNumber),
+		BlockHash:     tx.BlockHash,
+		TxIndex:       tx.TxIndex,
+		Timestamp:     parseUint(tx.Timestamp),
+		From:          tx.From,
+		FromLabel:     tx.FromLabel,
+		FromKind:      tx.FromKind,
+		FromSanctions: tx.FromSanctions,
+		To:            tx.To,
+		ToLabel:       tx.ToLabel,
+		ToKind:        tx.ToKind,
+		ToSanctions:   tx.ToSanctions,
+		Status:        tx.Status,
+		GasPrice:      tx.GasPrice,
+		Fee:           tx.Fee,
+		FeeDecimal:    tx.FeeDecimal,
+		Entries:       make([]EntryV2, len(tx.Transactions)),
+		Flows:         tx.Flows,
+	}
+
+	v2.Time = time.Unix(int64(v2.Timestamp), 0).UTC().Format(time.RFC3339)
+	if tx.Status != "" {
+		v2.Type, v2.Nonce, v2.GasUsed = parseUintPtr(tx.Type), parseUintPtr(tx.Nonce), parseUintPtr(tx.GasUsed)
+	}
+
+	for i, et := range tx.Transactions {
+		v2.Entries[i] = EntryV2{Index: i, LogIndex: et.LogIndex, Erc20Transaction: et}
+	}
+	return v2
+}
+
+// TokenUpdateV2 represents a change of token metadata in the schema v2 with typed fields.
+type TokenUpdateV2 struct {
+	SchemaVersion int      `json:"schemaVersion"`
+	Type          string   `json:"recordType"`
+	Token         Token    `json:"token"`
+	Previous      Token    `json:"previous"`
+	Changes       []string `json:"changes"`
+	Timestamp     uint64   `json:"timestamp"`
+	Time          string   `json:"time"`
+}
+
+// NewTokenUpdateV2 converts the token update into the schema v2.
+func NewTokenUpdateV2(u TokenUpdate) TokenUpdateV2 {
+	v2 := TokenUpdateV2{
+		SchemaVersion: SchemaV2,
+		Type:          u.Type,
+		Token:         u.Token,
+		Previous:      u.Previous,
+		Changes:       u.Changes,
+		Timestamp:     parseUint(u.Timestamp),
+	}
+	v2.Time = time.Unix(int64(v2.Timestamp), 0).UTC().Format(time.RFC3339)
+	return v2
+}
+
+// parseUint parses the decimal number, zero is provided for an invalid value.
+func parseUint(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// parseUintPtr parses the decimal number, nil is provided for an invalid value.
+func parseUintPtr(s string) *uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
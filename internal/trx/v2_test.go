@@ -0,0 +1,54 @@
+package trx
+
+import (
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+	"testing"
+)
+
+func TestNewTokenUpdateV2(t *testing.T) {
+	u := TokenUpdate{
+		Type:      TokenUpdated,
+		Token:     Token{Address: common.HexToAddress("0x01"), Symbol: "NEW"},
+		Previous:  Token{Address: common.HexToAddress("0x01"), Symbol: "OLD"},
+		Changes:   []string{"symbol"},
+		Timestamp: "1700000000",
+	}
+
+	v2 := NewTokenUpdateV2(u)
+	if v2.SchemaVersion != SchemaV2 || v2.Type != TokenUpdated || v2.Timestamp != 1700000000 || v2.Time != "2023-11-14T22:13:20Z" {
+		t.Errorf("unexpected update %+v", v2)
+	}
+	if v2.Token.Symbol != "NEW" || v2.Previous.Symbol != "OLD" || len(v2.Changes) != 1 {
+		t.Errorf("metadata not kept %+v", v2)
+	}
+
+	data, err := json.Marshal(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"schemaVersion":2`, `"timestamp":1700000000`, `"time":"2023-11-14T22:13:20Z"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("field %s missing in %s", field, data)
+		}
+	}
+}
+
+func TestCategoryOmitted(t *testing.T) {
+	data, err := json.Marshal(BlockchainTransaction{BlockNumber: "1", Timestamp: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"category"`) {
+		t.Errorf("empty category encoded in %s", data)
+	}
+
+	data, err = json.Marshal(NewBlockchainTransactionV2(BlockchainTransaction{Category: CategoryAdmin, BlockNumber: "1", Timestamp: "2"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"category":"ADMIN"`) {
+		t.Errorf("category missing in %s", data)
+	}
+}
@@ -0,0 +1,21 @@
+// Package trx implements transaction types.
+package trx
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Vault represents details of an ERC-4626 vault deposit or withdrawal.
+// Assets are denominated in the vault asset token, shares in the vault token itself.
+type Vault struct {
+	Asset    *Token         `json:"asset,omitempty"`
+	Assets   string         `json:"assets"`
+	Shares   string         `json:"shares"`
+	Owner    common.Address `json:"owner"`
+	Receiver common.Address `json:"receiver"`
+}
+
+// Erc777 represents details of an ERC-777 token operation.
+type Erc777 struct {
+	Operator     common.Address `json:"operator"`
+	Data         string         `json:"data,omitempty"`
+	OperatorData string         `json:"operatorData,omitempty"`
+}